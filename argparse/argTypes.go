@@ -3,19 +3,84 @@ package pc
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type T_ColNum int
 type T_ColNumbers []T_ColNum
 
+// T_filenames collects the values of the repeatable -file flag
+type T_filenames []string
+
+// String returns the filenames separated by comma
+func (f *T_filenames) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds a filename or all files matching a glob pattern.
+// A pattern without any matching file is an error.
+func (f *T_filenames) Set(val string) error {
+	matches, err := filepath.Glob(val)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		if strings.ContainsAny(val, "*?[") {
+			return fmt.Errorf("no file matches %q", val)
+		}
+		matches = []string{val}
+	}
+	*f = append(*f, matches...)
+	return nil
+}
+
+// T_filters collects the values of the repeatable -filter flag, all of them must match
+type T_filters []string
+
+// String returns the filters separated by comma
+func (f *T_filters) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds a filter
+func (f *T_filters) Set(val string) error {
+	*f = append(*f, val)
+	return nil
+}
+
 type T_flags struct {
-	Filename   string
+	Files      T_filenames
+	FileCol    bool
+	LineCol    bool
+	Union      bool
+	Watch      time.Duration // interval to run Command and redraw the table
+	Key        T_ColNum      // watch mode: column, that identifies a row between refreshes
+	Command    []string      // command line after '--' for -watch
+	Follow     bool
+	Stream     bool
+	SortMem    int    // stream mode: MB of rows sorted in memory, more rows are spilled to temporary files
+	Jobs       int    // number of concurrent workers parsing the input lines
+	Sample     int    // stream mode: number of lines for the column bounds and, for STDIN, the column widths
+	HeadEvery  int    // follow mode: print the header again every n rows
+	HeadWidth  bool   // follow mode: print the header again, when a column width changes
+	Strict     bool   // stop with an error at the first malformed row instead of handling it leniently
+	Ragged     string // policy for rows with more or less fields than the headline
+	Where      string // expression with conditions on the columns, that selects the rows
+	Lead       int    // number of leading rows after the headline, that are never filtered
+	Count      bool   // print the footer 'N of M rows matched'
+	Rename     string // new titles of the output columns like '2=Pod,STATUS=State'
+	FilterInv  bool   // select the rows, that do not match the -filter patterns
+	FilterCase bool   // match the -filter patterns case-insensitive
+	After      int    // number of rows after a -filter match, that are kept as context
+	Before     int    // number of rows before a -filter match, that are kept as context
+	Null       string // marker for cells missing in an input of -union
 	Header     string
 	Sep        string
 	Colsep     string
-	Filter     string
+	Filter     T_filters
 	Gcol       T_ColNum
 	GcolVal    bool
 	SortCol    T_ColNum
@@ -36,10 +101,22 @@ type T_flags struct {
 	Manual     bool
 	Grouping   bool
 	MoreBlanks bool
+	Fixed      bool
+	Icsv       bool
+	Itsv       bool
+	Ijson      bool
+	Ilogfmt    bool
+	Istanza    bool
+	Jarr       string // array handling of JSON input: join, index or json
+	Auto       bool
 	Version    bool
-	verify     bool
+	Verify     bool
 	Mark       string // Regex pattern for marking lines
+	Pattern    string // Regex with capture groups to parse the input lines
+	NoMatch    string // handling of lines not matching Pattern: drop, keep or append
 	Columns    T_ColNumbers
+	ColSpecs   []string // column arguments, that are resolved against the headline, see ResolveColumns
+	Widths     []int    // explicit column widths for fixed-width input
 }
 
 // AppVersion holds the application version, set from main package
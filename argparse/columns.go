@@ -0,0 +1,216 @@
+package pc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// colRange matches a range of column numbers like 2:5, 4: or :-2
+var colRange = regexp.MustCompile(`^(-?\d*):(-?\d*)$`)
+
+// isColNumbers returns true, if the column argument is a positive number or a closed range
+// of positive numbers, that need no headline
+func isColNumbers(val string) bool {
+	if res := colRange.FindStringSubmatch(val); res != nil {
+		return isPositive(res[1]) && isPositive(res[2])
+	}
+	return isPositive(val)
+}
+
+// isPositive returns true, if val consists of digits only
+func isPositive(val string) bool {
+	_, err := strconv.Atoi(val)
+	return err == nil && !strings.HasPrefix(val, "-")
+}
+
+// checkColSpecs returns an error for a column argument with a broken regex
+func checkColSpecs(specs []string) error {
+	for _, spec := range specs {
+		spec = strings.TrimPrefix(spec, "!")
+		if isColRegex(spec) {
+			if _, err := regexp.Compile(spec[1 : len(spec)-1]); err != nil {
+				return NewError(ErrParam, "column %s must be a valid regex. %v", spec, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isColRegex returns true, if the column argument is a regex like /^cpu_/
+func isColRegex(spec string) bool {
+	return len(spec) > 1 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/")
+}
+
+// ResolveColumns returns the numbers of the columns selected by the column arguments for a
+// table of width columns with the titles of header, nil for data without headline.
+// An argument is a column number, negative numbers count from the last column (-1),
+// a range n:m, open ranges n: and :m, a column title, a /regex/ for the titles or one of
+// them prefixed by '!' to exclude the columns. With only exclusions all other columns are selected.
+func ResolveColumns(specs []string, header []string, width int) (T_ColNumbers, error) {
+	var include T_ColNumbers
+	exclude := map[T_ColNum]bool{}
+	onlyExcludes := true
+	for _, spec := range specs {
+		cols, err := resolveColumn(strings.TrimPrefix(spec, "!"), header, width)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(spec, "!") {
+			for _, c := range cols {
+				exclude[c] = true
+			}
+			continue
+		}
+		onlyExcludes = false
+		include = append(include, cols...)
+	}
+	if onlyExcludes {
+		for i := 1; i <= width; i++ {
+			include = append(include, T_ColNum(i))
+		}
+	}
+	var cn T_ColNumbers
+	for _, c := range include {
+		if !exclude[c] {
+			cn = append(cn, c)
+		}
+	}
+	if len(cn) == 0 {
+		return nil, NewError(ErrParam, "the column arguments '%s' select no column.", strings.Join(specs, " "))
+	}
+	return cn, nil
+}
+
+// resolveColumn returns the numbers of the columns selected by one column argument
+func resolveColumn(spec string, header []string, width int) (T_ColNumbers, error) {
+	if isColRegex(spec) {
+		if header == nil {
+			return nil, NewError(ErrParam, "column %s needs a headline.", spec)
+		}
+		re, err := regexp.Compile(spec[1 : len(spec)-1])
+		if err != nil {
+			return nil, NewError(ErrParam, "column %s must be a valid regex. %v", spec, err)
+		}
+		var cn T_ColNumbers
+		for i, title := range header {
+			if re.MatchString(title) {
+				cn = append(cn, T_ColNum(i+1))
+			}
+		}
+		return cn, nil
+	}
+	if res := colRange.FindStringSubmatch(spec); res != nil {
+		n, err := columnNumber(res[1], 1, width)
+		if err != nil {
+			return nil, err
+		}
+		m, err := columnNumber(res[2], width, width)
+		if err != nil {
+			return nil, err
+		}
+		var cn T_ColNumbers
+		if n < m {
+			for i := n; i <= m; i++ { // upwards
+				cn = append(cn, i)
+			}
+		} else {
+			for i := n; i >= m; i-- { // downwards
+				cn = append(cn, i)
+			}
+		}
+		return cn, nil
+	}
+	if _, err := strconv.Atoi(spec); err == nil {
+		n, err := columnNumber(spec, 0, width)
+		return T_ColNumbers{n}, err
+	}
+	if header == nil {
+		return nil, NewError(ErrParam, "column '%s' needs a headline.", spec)
+	}
+	for _, equal := range []func(string, string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		for i, title := range header {
+			if equal(title, spec) {
+				return T_ColNumbers{T_ColNum(i + 1)}, nil
+			}
+		}
+	}
+	return nil, NewError(ErrParam, "column '%s' is not in the headline, available are: %s", spec, strings.Join(header, ", "))
+}
+
+// columnNumber converts a column number, negative numbers count from the last column.
+// An empty value is def.
+func columnNumber(val string, def int, width int) (T_ColNum, error) {
+	if val == "" {
+		return T_ColNum(def), nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, NewError(ErrParam, "column %s is not a number.", val)
+	}
+	if n < 0 {
+		n += width + 1
+	}
+	if n < 1 {
+		return 0, NewError(ErrParam, "column %s is out of range, the input has %d columns.", val, width)
+	}
+	return T_ColNum(n), nil
+}
+
+// renameParts splits the -rename parameter like '2=Pod,STATUS=State' into the pairs of
+// column and new title
+func renameParts(rename string) ([][2]string, error) {
+	var pairs [][2]string
+	if rename == "" {
+		return pairs, nil
+	}
+	for _, part := range strings.Split(rename, ",") {
+		col, title, ok := strings.Cut(part, "=")
+		if !ok || col == "" {
+			return nil, NewError(ErrParam, "-rename='%s': '%s' must be like column=title.", rename, part)
+		}
+		pairs = append(pairs, [2]string{col, title})
+	}
+	return pairs, nil
+}
+
+// RenameColumns sets the titles of the output headline as defined by -rename. A column is
+// given by its number in the output or its title, a title renames all columns with it.
+func RenameColumns(header []string, rename string) error {
+	pairs, err := renameParts(rename)
+	if err != nil {
+		return err
+	}
+	orig := append([]string{}, header...) // titles are looked up before renaming
+	for _, pair := range pairs {
+		col, title := pair[0], pair[1]
+		if n, err := strconv.Atoi(col); err == nil {
+			if n < 1 || n > len(header) {
+				return NewError(ErrParam, "-rename: column %d is out of range, the output has %d columns.", n, len(header))
+			}
+			header[n-1] = title
+			continue
+		}
+		found := false
+		for _, equal := range []func(string, string) bool{
+			func(a, b string) bool { return a == b },
+			strings.EqualFold,
+		} {
+			for i, t := range orig {
+				if equal(t, col) {
+					header[i], found = title, true
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return NewError(ErrParam, "-rename: column '%s' is not in the headline, available are: %s", col, strings.Join(orig, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package pc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// T_errclass is the class of an error, every class has its own exit code
+type T_errclass int
+
+// The error classes, their value is the exit code of pc
+const (
+	ErrInternal T_errclass = iota + 1 // unexpected error
+	ErrParam                          // wrong or conflicting parameters
+	ErrInput                          // a file or STDIN can not be read
+	ErrData                           // malformed input data, e.g. a broken CSV record
+	ErrOutput                         // the output or a temporary file can not be written
+)
+
+// T_error is an error with its class and, as far as known, the position in the input
+type T_error struct {
+	Class  T_errclass
+	File   string // name of the input
+	Line   int    // line or record number in the input, starting with 1
+	Column int    // column or field number in the line, starting with 1
+	Err    error
+}
+
+// Error returns the message prefixed by the position like 'file:line:column: message'
+func (e *T_error) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		if pos != "" {
+			pos += ":"
+		}
+		pos += strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			pos += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	if pos == "" {
+		return e.Err.Error()
+	}
+	return pos + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *T_error) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns an error of the class with a formatted message like fmt.Errorf
+func NewError(class T_errclass, format string, a ...any) *T_error {
+	return &T_error{Class: class, Err: fmt.Errorf(format, a...)}
+}
+
+// ErrorAt returns the error of the class with the position in the input.
+// A T_error keeps its class and gets the position, as far as it is not known yet.
+// Errors of parameters have no position.
+func ErrorAt(class T_errclass, err error, file string, line, column int) *T_error {
+	var te *T_error
+	if errors.As(err, &te) {
+		if te.Class == ErrParam {
+			return te
+		}
+		nte := *te
+		if nte.File == "" {
+			nte.File = file
+		}
+		if nte.Line == 0 {
+			nte.Line, nte.Column = line, column
+		}
+		return &nte
+	}
+	return &T_error{Class: class, File: file, Line: line, Column: column, Err: err}
+}
+
+// ExitCode returns the exit code for the error: the value of its class, 1 for other errors
+func ExitCode(err error) int {
+	var te *T_error
+	if errors.As(err, &te) {
+		return int(te.Class)
+	}
+	return int(ErrInternal)
+}
+
+// Fail prints the error and exits with the exit code of its class
+func Fail(err error) {
+	if ExitCode(err) == int(ErrParam) {
+		fmt.Println("ERROR: " + err.Error())
+		fmt.Println("program 'pc' is exited because of error in parameter!")
+	} else {
+		fmt.Fprintln(os.Stderr, "ERROR: "+err.Error())
+	}
+	os.Exit(ExitCode(err))
+}
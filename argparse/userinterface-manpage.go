@@ -14,7 +14,7 @@ SYNOPSYS
 
     options:        [-file=input-filename] [-header='col1-header col2-header coln-header'] [-colsep='|'] [-filter='string]
                     [-csv] [-json] [-jtc] [-ts] [-cs] [-rh] [-pp] [-num] [-version] [-h,-help] [-man]
-    column-numbers: [ 1 2 n:m ], a range of columns can be given with [n:m]
+    column-numbers: [ 1 2 n:m -1 n: :m NAME /regex/ !n ], a range of columns can be given with [n:m]
 
     !! All the options must be set before the column-numbers!
 
@@ -24,7 +24,8 @@ DESCRIPTION
     pc - formats text columns from stdin or file and print them as a ASCII table or CSV or JSON.
          With the optional parameters the look and content of the output can be modified.
          The columns for output can be selected by single numbers seperated by space or one or more ranges seperated by colon.
-         The input should have the same number of columns in each line.
+         The input should have the same number of columns in each line,
+         rows with more or less columns are handled as defined by -ragged.
          The formated result is printed to stdout.
 
          All named parameters must be defined before the column numbers.
@@ -34,7 +35,11 @@ DESCRIPTION
     The parameters:
 
         -file=filename                      read the text from this file,
-                                            if there is also data from STDIN, this is added together
+                                            if there is also data from STDIN, this is added together.
+                                            -file can be repeated and can be a glob pattern like -file='app-*.log',
+                                            the headline is only taken from the first file (unless -nhl is set).
+                                            Files and STDIN compressed with gzip or bzip2 are uncompressed,
+                                            xz and zstd compressed data is uncompressed by the local 'xz' or 'zstd' command.
 
 
         -header='...'    Headerline,        if the text has no headers, you can define headers.
@@ -49,9 +54,103 @@ DESCRIPTION
                                             Typically useful when input is a preformatted ASCII table with blanks as delimiters.
                                             If a headerline is defined with -header=..., then the fields must also delimited by
                                             two blanks ore more.
+        -fixed            FixedWidth        split the columns at the display positions, where the fields of the headline start.
+                                            Values with single blanks (e.g. in output of ps, df, docker ps) stay in one cell.
+                                            Right adjusted columns are detected by the blank gutters in the data lines.
+        -widths=8,12,...  ColumnWidths      fixed-width input with explicit display widths of the columns,
+                                            the rest of the line forms the last column. Implies -fixed.
+        -icsv             InputCSV          parse the input as RFC 4180 CSV: quoted fields may contain the separator,
+                                            linefeeds and doubled "" quotes, the quotes are removed. A leading BOM is ignored.
+                                            The separator is ',' or the character given with -sep.
+        -itsv             InputTSV          like -icsv, but the fields are separated by tabs.
+        -ijson            InputJSON         parse the input as JSON array of objects or as newline delimited JSON objects.
+                                            Each object is a row, the header is the union of all keys. Nested objects
+                                            get dotted keys like metadata.name. For 'kubectl get -o json' the items are used.
+        -jarr=join        JsonArrays        handling of arrays in JSON input: join the values with ',' (default),
+                                            index - a column per element (tags.0, tags.1), json - the array as JSON text.
+        -ilogfmt          InputLogfmt       parse each line as logfmt key=value pairs, e.g. level=info msg="started" dur=12ms.
+                                            The header is the union of all keys, missing keys stay empty.
+        -istanza          InputStanza       parse blocks of 'Key: value' or 'Key=value' lines separated by blank lines,
+                                            e.g. from kubectl describe, /proc/cpuinfo or systemctl show. Each block is a row,
+                                            the keys are the columns. Indented lines continue the previous value.
+                                            Lines with several key="value" pairs (lsblk -P) are a row of their own.
+        -pattern='regex'  Pattern           parse each line with a regex, the capture groups become the columns,
+                                            the group names the header, e.g. -pattern='(?P<ts>\S+ \S+) (?P<level>\w+) (?P<msg>.*)'
+        -nomatch=drop     NoMatch           lines not matching -pattern are dropped (default), kept in an additional
+                                            column UNMATCHED (keep) or appended to the last cell of the previous row (append),
+                                            e.g. for stack traces.
+        -fcol             FileColumn        insert a first column FILE with the name of the input file (STDIN for stdin).
+        -lcol             LineColumn        insert a column LINE with the line number of the row in its input,
+                                            for -icsv, -ijson, -ilogfmt, -istanza and -pattern the record number.
+                                            The FILE and LINE columns count for the column numbers, their titles
+                                            precede the titles of -header.
+        -union            Union             combine several inputs by aligning the columns by their titles in the headlines
+                                            instead of their position. Columns only in later inputs are appended.
+                                            The inputs need headlines, -nhl and -header can not be used with it.
+        -null=''          NullMarker        value for cells of columns, that are missing in an input of -union.
+        -follow           Follow            like 'tail -f': print the lines of the file (or STDIN) as they arrive,
+                                            aligned to the column widths seen so far. Works with line based input,
+                                            -filter, -where, -mark and column arguments. Sorting, grouping, -num, -rh, -fs,
+                                            -fcol, -lcol, -union, -auto, CSV/JSON output and the record based input modes
+                                            are an error.
+        -hevery=n         HeadEvery         follow mode: print the header again every n rows.
+        -hwidth           HeadWidth         follow mode: print the header again, when a column width grows.
+        -watch=2s -- cmd  Watch             run the command after '--' every interval and redraw its output in place,
+                                            like 'watch'. Cells changed since the last refresh are shown in reverse
+                                            video, new rows in green. A status line counts the added and removed rows.
+                                            Grouping, -num, -count and CSV/JSON output are an error.
+        -key=1            KeyColumn         watch mode: number of the (output) column, that identifies a row between
+                                            two refreshes, default=1.
+        -stream           Stream            print the rows while they are read, without holding the data in memory,
+                                            for huge inputs. The column widths of a file are measured in a first pass,
+                                            for STDIN they are taken from the first -sample lines and grow later.
+                                            Grouping, -num, -rh, -fs, CSV/JSON output and the record based input
+                                            modes need all rows, with them the data is buffered as usual.
+        -sample=1000      Sample            stream mode: number of lines for the column widths of STDIN.
+        -sortmem=256      SortMemory        stream mode: MB of rows, that -sortcol sorts in memory. Beyond that, sorted
+                                            runs are written to temporary files and merged for the output, so inputs
+                                            larger than the memory can be sorted. A file larger than this is sorted in
+                                            stream mode also without -stream, if no other parameter needs all rows.
+        -j=n              Jobs              number of concurrent workers, that parse and filter inputs of more than
+                                            10000 lines in chunks. The order of the lines is kept. Default is the
+                                            number of CPUs, -j=1 parses sequentially.
+        -where='expr'     Where             process only rows, for which the expression is true, e.g.
+                                            -where='status != "Running" && restarts > 3 || name ~ /^api-/'
+                                            Columns are given as $N or by their title in the headline (or -header).
+                                            == != < <= > >= compare numerically, if both sides are numbers, else as text.
+                                            ~ and !~ match a /regex/, && || ! (or: and or not) and parentheses combine
+                                            the conditions, a column alone is true, if it is not empty. The headline is kept.
+        -lead=n           Lead              the first n rows after the headline (with -nhl the first n rows) are never
+                                            dropped by -filter and -where, e.g. context lines at the top of the input.
+        -count            Count             print the footer 'N of M rows matched' below the output, the rows
+                                            selected by -filter and -where of all rows. For CSV and JSON output on STDERR.
+        -ragged=policy    Ragged            rows with more or less fields than the headline (or the -header):
+                                            pad - add empty fields, truncate - also drop surplus fields,
+                                            merge-last - also keep the rest of the line with its spacing in the last
+                                            column, e.g. for free text messages, error - stop at the first such row with
+                                            its line number, report - print the line numbers of the rows on STDERR.
+                                            Default: keep the rows.
+        -strict           Strict            stop with an error at the first malformed row: a row with more or less
+                                            fields than the headline (like -ragged=error), a row without the -sortcol
+                                            column or a JSON value without title. Without -strict such rows are handled leniently.
+        -auto             AutoDetect        detect the input format from the first 50 lines: delimited by , ; tab or |,
+                                            separated by blanks or two blanks, fixed-width, JSON or NDJSON.
+                                            Explicitly set input parameters (-sep, -mb, -fixed, -icsv, ...) win.
+                                            With -v the detected format and its confidence is printed to STDERR.
+        -rename='2=Pod,STATUS=State'
+                          Rename            new titles of the output columns, given by their number in the output or
+                                            their title, also for the -header line and the keys of CSV and JSON output.
+                                            The order of the output columns and copies of a column are defined by the
+                                            column arguments, e.g. pc -rename='3=NAME2' NAME STATUS NAME
         -w=1                                no of blanks between colums seperator and column content, default is 1.
         -colsep='|'       ColumnSeparator   define the character to separate the columns, default='|'.
         -filter='regex'   Filter lines,     process only lines where 'string' or 'regex' matches.
+                                            'n=regex' only matches column n. The headline is always kept.
+                                            -filter can be repeated, then all filters must match, e.g. -filter=ERROR -filter='3=^disk'
+        -fv               FilterInvert      process only lines, that do not match the filters, like 'grep -v'.
+        -fi               FilterIgnoreCase  match the filters case-insensitive, like 'grep -i'.
+        -A=n -B=n -C=n    Context           keep n rows after (-A), before (-B) or around (-C) each filter match
+                                            as context, like grep. -count only counts the matching rows.
         -mark='regex'     Mark lines,       output lines matching the regex will be colored (ANSI yellow).
         -sortcol=colnum:  SortColumn        number of column, to sort for. Only one column can be defined for sort.
                                             Number refers to the number of the output column.
@@ -85,10 +184,29 @@ DESCRIPTION
                                             that should printed out. To rearrange the columns
                                             the columns can given in the wanted order.
                                             This parameters must be defined after all other parameters.
+        -2  -1       NegativeNumbers        count from the last column, -1 is the last column.
+        n:  :m       OpenRanges             from column n to the last column, from the first to column m.
+        NAME         ColumnTitle            the column with this title in the headline (or -header), if no title is
+                                            equal, a title equal except for the case. Unknown titles are an error, that
+                                            lists the available titles.
+        /regex/      ColumnRegex            all columns with a title matching the regex.
+        !3 !NAME     Exclusion              the column is not printed, '!' can precede all forms above.
+                                            With only exclusions all other columns are printed.
+                                            A negative number can be the first column argument: pc -ts -1 NAME.
+                                            The arguments after '--' are column arguments too (without -watch).
 
         -h -help          Help,             print help and exit
         -man              Manual,           print help and manual, then exit
 
+EXIT STATUS
+    0  success
+    1  unexpected error
+    2  wrong or conflicting parameters
+    3  a file or STDIN can not be read
+    4  malformed input data, e.g. a broken CSV record or, with -strict, a malformed row
+    5  the output or a temporary file can not be written
+    Errors in the input are reported with their position like 'file:line:column: message'.
+
 AUTHOR
     written by Dirk Jäger (dirk.jaeger.dj@gmail.com)
 
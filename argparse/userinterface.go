@@ -4,18 +4,94 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var CmdParams T_flags
 
+// explicitFlags holds the names of all flags, that are set on the command line
+var explicitFlags = map[string]bool{}
+
+// IsFlagSet returns true, if the flag with this name is explicitly set on the command line.
+func IsFlagSet(name string) bool {
+	return explicitFlags[name]
+}
+
+// splitArgs splits the positional arguments at '--' into the column numbers before and
+// the command line after it. Without -watch the arguments after '--' are column arguments,
+// so a negative column number like -2 can be the first one.
+func splitArgs() ([]string, []string) {
+	args := flag.Args()
+	if !IsFlagSet("watch") {
+		cols := []string{}
+		for _, val := range args {
+			if val != "--" {
+				cols = append(cols, val)
+			}
+		}
+		return cols, nil
+	}
+	for i, val := range os.Args[1:] {
+		if val == "--" {
+			command := os.Args[i+2:]
+			cols := args[:len(args)-len(command)]
+			if len(cols) > 0 && cols[len(cols)-1] == "--" {
+				cols = cols[:len(cols)-1]
+			}
+			return cols, command
+		}
+	}
+	return args, nil
+}
+
+// negativeColumn matches a negative column number or a range starting with one, like -2 or -3:
+var negativeColumn = regexp.MustCompile(`^-\d+(:-?\d*)?$`)
+
+// ColumnArgs returns the command line arguments with a '--' inserted before the first
+// negative column number like -2, so it is not parsed as an unknown flag of fs.
+// A negative number, that is the value of the preceding flag like '-null -1', is kept.
+func ColumnArgs(fs *flag.FlagSet, args []string) []string {
+	isValue := false // the argument is the value of the preceding flag
+	for i, val := range args {
+		if isValue {
+			isValue = false
+			continue
+		}
+		if val == "--" || !strings.HasPrefix(val, "-") {
+			break // the flags end here
+		}
+		if negativeColumn.MatchString(val) {
+			cols := append([]string{}, args[:i]...)
+			return append(append(cols, "--"), args[i:]...)
+		}
+		if name := strings.TrimLeft(val, "-"); !strings.Contains(name, "=") {
+			if f := fs.Lookup(name); f != nil {
+				b, ok := f.Value.(interface{ IsBoolFlag() bool })
+				isValue = !ok || !b.IsBoolFlag()
+			}
+		}
+	}
+	return args
+}
+
 // getArgsColNumbers collect all unknown parameters, if there are int values or ranges of int:int, as column numbers.
 // ranges are supported - m:n, upwards 3:6 and downwards 6:3
-func getArgsColNumbers() T_ColNumbers {
+// Other column arguments like titles, /regex/, !exclusions, negative numbers or open ranges
+// need the headline, then all arguments are returned as specs for ResolveColumns.
+func getArgsColNumbers() (T_ColNumbers, []string) {
 	var cn T_ColNumbers
 	var error bool
-	for _, val := range flag.Args() {
+	cols, _ := splitArgs()
+	for _, val := range cols {
+		if !isColNumbers(val) {
+			return nil, cols
+		}
+	}
+	for _, val := range cols {
 		if strings.Contains(val, ":") { // if a range
 			res := strings.Split(val, ":") // split into fields
 			if len(res) != 2 {
@@ -53,36 +129,248 @@ func getArgsColNumbers() T_ColNumbers {
 	}
 	if error {
 		fmt.Println("program 'pc' is exited because of error in parameter!")
-		os.Exit(1)
+		os.Exit(int(ErrParam))
+	}
+	return cn, nil
+}
+
+// watchCommand returns the command line after '--'
+func watchCommand() []string {
+	_, command := splitArgs()
+	return command
+}
+
+// getWidths converts the comma separated list of the -widths parameter into a slice of column widths.
+func getWidths(val string) []int {
+	var widths []int
+	if val == "" {
+		return widths
 	}
-	return cn
+	for _, w := range strings.Split(val, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil || i <= 0 {
+			Fail(NewError(ErrParam, "width %s in -widths=%s is not a positive integer.", w, val))
+		}
+		widths = append(widths, i)
+	}
+	return widths
 }
 
-// fix_params disable CmdParams, that make no sense,when output to CSV or JSON.
+// fix_params checks CmdParams and exits with an error message, if a parameter is wrong.
 func fix_params() {
-	if CmdParams.Csv || CmdParams.Json {
-		// CmdParams.Ts = false
-		CmdParams.Fs = false
-		CmdParams.Pp = false
+	if err := Check(&CmdParams); err != nil {
+		Fail(err)
 	}
-	CmdParams.Grouping = CmdParams.Gcol > 0
+}
+
+// filterColumn matches a -filter for one column like 3=regex
+var filterColumn = regexp.MustCompile(`^(\d+)=(.*)$`)
+
+// FilterParts splits the -filter parameter into the index of the filtered column, -1 for
+// the whole line, and the regex.
+func FilterParts(filter string) (int, string) {
+	if res := filterColumn.FindStringSubmatch(filter); res != nil {
+		if i, err := strconv.Atoi(res[1]); err == nil {
+			return i - 1, res[2]
+		}
+	}
+	return -1, filter
+}
+
+// t_blocker is a parameter, that is not possible in a mode, and if it is set
+type t_blocker struct {
+	name string
+	set  bool
+}
+
+// firstBlocker returns the name of the first set parameter or "" if none is set
+func firstBlocker(blockers []t_blocker) string {
+	for _, b := range blockers {
+		if b.set {
+			return b.name
+		}
+	}
+	return ""
+}
+
+// followBlockers returns the first parameter, that needs all rows or a record based input
+// and so is not possible with -follow, or "" if there is none.
+func followBlockers(p *T_flags) string {
+	return firstBlocker([]t_blocker{
+		{"sortcol", p.SortCol > 0},
+		{"gcol", p.Gcol > 0},
+		{"num", p.Num},
+		{"rh", p.Rh},
+		{"fs", p.Fs},
+		{"csv", p.Csv},
+		{"json", p.Json},
+		{"auto", p.Auto},
+		{"icsv", p.Icsv},
+		{"itsv", p.Itsv},
+		{"ijson", p.Ijson},
+		{"ilogfmt", p.Ilogfmt},
+		{"istanza", p.Istanza},
+		{"pattern", p.Pattern != ""},
+		{"union", p.Union},
+		{"fcol", p.FileCol},
+		{"lcol", p.LineCol},
+	})
+}
+
+// watchBlockers returns the first output parameter, that the redrawn table of -watch does
+// not support, or "" if there is none.
+func watchBlockers(p *T_flags) string {
+	return firstBlocker([]t_blocker{
+		{"gcol", p.Gcol > 0},
+		{"num", p.Num},
+		{"count", p.Count},
+		{"csv", p.Csv},
+		{"json", p.Json},
+	})
+}
+
+// Check disables parameters, that make no sense when output to CSV or JSON, sets the values
+// derived from other parameters and returns an error of class ErrParam for wrong or
+// conflicting parameters.
+// It is used for the command line parameters as well as for the options of the table package.
+func Check(p *T_flags) error {
+	if p.Csv || p.Json {
+		// p.Ts = false
+		p.Fs = false
+		p.Pp = false
+	}
+	p.Grouping = p.Gcol > 0
+	if len(p.Widths) > 0 {
+		p.Fixed = true
+	}
+	if p.Jarr != "join" && p.Jarr != "index" && p.Jarr != "json" {
+		return NewError(ErrParam, "-jarr=%s is unknown, use join, index or json.", p.Jarr)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil || re.NumSubexp() == 0 {
+			return NewError(ErrParam, "-pattern='%s' must be a valid regex with capture groups. %v", p.Pattern, err)
+		}
+	}
+	if _, err := regexp.Compile(p.Mark); err != nil {
+		return NewError(ErrParam, "-mark='%s' must be a valid regex. %v", p.Mark, err)
+	}
+	for _, filter := range p.Filter {
+		_, pattern := FilterParts(filter)
+		if _, err := regexp.Compile(pattern); err != nil {
+			return NewError(ErrParam, "-filter='%s' must be a valid regex. %v", filter, err)
+		}
+	}
+	if err := checkColSpecs(p.ColSpecs); err != nil {
+		return err
+	}
+	if _, err := renameParts(p.Rename); err != nil {
+		return err
+	}
+	if p.Rename != "" && p.Nhl && p.Header == "" {
+		return NewError(ErrParam, "-rename needs a headline, with -nhl define it with -header.")
+	}
+	if p.After < 0 || p.Before < 0 {
+		return NewError(ErrParam, "-A, -B and -C must not be negative.")
+	}
+	switch p.Ragged {
+	case "", "pad", "truncate", "merge-last", "error", "report":
+	default:
+		return NewError(ErrParam, "-ragged=%s is unknown, use pad, truncate, merge-last, error or report.", p.Ragged)
+	}
+	if p.Strict && p.Ragged == "" {
+		p.Ragged = "error"
+	}
+	if p.NoMatch != "drop" && p.NoMatch != "keep" && p.NoMatch != "append" {
+		return NewError(ErrParam, "-nomatch=%s is unknown, use drop, keep or append.", p.NoMatch)
+	}
+	if p.Union && p.Nhl {
+		return NewError(ErrParam, "-union aligns the columns by the headlines and can not be combined with -nhl.")
+	}
+	if p.Union && p.Header != "" {
+		return NewError(ErrParam, "-union aligns the columns by the headlines of the inputs and can not be combined with -header.")
+	}
+	if p.Follow && len(p.Files) > 1 {
+		return NewError(ErrParam, "-follow can only follow one file.")
+	}
+	if name := followBlockers(p); p.Follow && name != "" {
+		return NewError(ErrParam, "-follow prints the lines as they arrive and can not be combined with -%s.", name)
+	}
+	if p.Watch < 0 || (p.Watch > 0 && len(p.Command) == 0) {
+		return NewError(ErrParam, "-watch needs a positive interval and a command after '--', e.g. pc -watch=2s -- kubectl get pods")
+	}
+	if name := watchBlockers(p); p.Watch > 0 && name != "" {
+		return NewError(ErrParam, "-watch redraws the output as ASCII table and can not be combined with -%s.", name)
+	}
+	if p.Watch == 0 && len(p.Command) > 0 {
+		return NewError(ErrParam, "the command '%s' after '--' is only used with -watch.", strings.Join(p.Command, " "))
+	}
+	if p.Lead < 0 {
+		return NewError(ErrParam, "-lead must not be negative.")
+	}
+	if p.Sample <= 0 || p.SortMem <= 0 || p.Jobs <= 0 {
+		return NewError(ErrParam, "-sample, -sortmem and -j must be positive numbers.")
+	}
+	if p.Watch > 0 && p.Follow {
+		return NewError(ErrParam, "-watch and -follow can not be combined.")
+	}
+	if p.Sep == "" {
+		return NewError(ErrParam, "-sep must not be empty.")
+	}
+	// CSV and TSV input have their own default separator
+	if p.Itsv {
+		p.Sep = "\t"
+	} else if p.Icsv && p.Sep == " " {
+		p.Sep = ","
+	}
+	return nil
 }
 
 // EvalFlags evaluate all command line flags and set a struct with their values.
 func EvalFlags() {
 	flag.Usage = cmdManpage // helptext for parameters must be defined at function 'cmdParams'
 	// Global Flags with values
-	filenmPtr := flag.String("file", "", "Filename, read the text from this file")
+	var files T_filenames
+	flag.Var(&files, "file", "Filename, read the text from this file, can be repeated and can be a glob pattern")
 	headerPtr := flag.String("header", "", "Headerline, if the text has no headers, you can define headers. They must be defined in the original order of the incoming text. Headers are left adjeusted, if they not start with a dash (-), then they right adjusted.")
 	sepPtr := flag.String("sep", " ", "InputColumnSeperator, define the character to separate the columns, when parsing in, default=' '")
 	colsepPtr := flag.String("colsep", "|", "ColumnSeperator, define the character to separate the columns, default='|'")
-	filterPtr := flag.String("filter", "", "Filterpattern, process only lines where 'filter-string' is found")
+	var filters T_filters
+	flag.Var(&filters, "filter", "Filterpattern, process only lines where 'filter-string' is found, 'n=regex' only searches column n, can be repeated, all filters must match")
+	fvPtr := flag.Bool("fv", false, "FilterInvert, process only lines, that do not match -filter, like 'grep -v'")
+	fiPtr := flag.Bool("fi", false, "FilterIgnoreCase, match -filter case-insensitive, like 'grep -i'")
+	afterPtr := flag.Int("A", 0, "After, keep n rows after each -filter match as context, like 'grep -A'")
+	beforePtr := flag.Int("B", 0, "Before, keep n rows before each -filter match as context, like 'grep -B'")
+	contextPtr := flag.Int("C", 0, "Context, keep n rows before and after each -filter match, like 'grep -C'")
 	markPtr := flag.String("mark", "", "Regex pattern to mark output lines with color")
+	patternPtr := flag.String("pattern", "", "Pattern, regex with (named) capture groups, the groups become the columns and their names the header")
+	nomatchPtr := flag.String("nomatch", "drop", "NoMatch, handling of lines not matching -pattern: drop, keep or append")
 	gcolnrPtr := flag.Int("gcol", 0, "GroupColumn, write a separator when the value in this column is different to the value in the previous line to group the values in this column. Number refers to the number of the output column")
 	gcolvalPtr := flag.Bool("gcolval", false, "GroupColumnValues, Do not replace values in Groupcol by '' ")
+	keyPtr := flag.Int("key", 1, "KeyColumn, watch mode: number of the column, that identifies a row between two refreshes, default=1")
 	sortColPtr := flag.Int("sortcol", 0, "SortColumn, number of column, to sort for. Only one column ca be defined for sort.")
 	colswPtr := flag.Int("w", 1, "colSepWidth, no of chars used to seperate output columns, default=1")
+	watchPtr := flag.Duration("watch", 0, "Watch, run the command after '--' every interval, e.g. -watch=2s, and redraw the table")
+	jarrPtr := flag.String("jarr", "join", "JsonArrays, handling of arrays in JSON input: join, index or json")
+	sortmemPtr := flag.Int("sortmem", 256, "SortMemory, stream mode: MB of rows sorted in memory, more rows are sorted in temporary files, default=256")
+	jobsPtr := flag.Int("j", runtime.NumCPU(), "Jobs, number of concurrent workers parsing and filtering large inputs, default=number of CPUs")
+	samplePtr := flag.Int("sample", 1000, "Sample, stream mode: number of lines used for the column widths of STDIN, default=1000")
+	heveryPtr := flag.Int("hevery", 0, "HeadEvery, follow mode: print the header again every n rows")
+	nullPtr := flag.String("null", "", "Null, marker for cells, that are missing in an input of -union, default=''")
+	widthsPtr := flag.String("widths", "", "Widths, comma separated display widths of the input columns for fixed-width input, e.g. -widths=8,12,6")
 	// Boolean flags
+	fcolPtr := flag.Bool("fcol", false, "FileColumn, insert a first column FILE with the name of the input file of the row")
+	streamPtr := flag.Bool("stream", false, "Stream, print the rows as they are read instead of holding all data in memory")
+	followPtr := flag.Bool("follow", false, "Follow, print new lines of the file or STDIN as they arrive, like 'tail -f'")
+	hwidthPtr := flag.Bool("hwidth", false, "HeadWidth, follow mode: print the header again, when a column width changes")
+	strictPtr := flag.Bool("strict", false, "Strict, stop with an error at the first malformed row, e.g. a row with more or less fields than the headline")
+	renamePtr := flag.String("rename", "", "Rename, new titles of the output columns by number or title, e.g. -rename='2=Pod,STATUS=State'")
+	leadPtr := flag.Int("lead", 0, "Lead, number of leading rows after the headline, that -filter and -where never drop")
+	countPtr := flag.Bool("count", false, "Count, print the footer 'N of M rows matched' for -filter and -where")
+	wherePtr := flag.String("where", "", "Where, select the rows by conditions on their columns, e.g. -where='status != \"Running\" && restarts > 3'")
+	raggedPtr := flag.String("ragged", "", "Ragged, rows with more or less fields than the headline: pad, truncate, merge-last, error or report, default=keep them")
+	unionPtr := flag.Bool("union", false, "Union, align the rows of all inputs by the column titles of their headlines")
+	lcolPtr := flag.Bool("lcol", false, "LineColumn, insert a column LINE with the line (or record) number of the row in its input")
 	nfPtr := flag.Bool("nf", false, "no format, don't format the colums for common column width")
 	nnPtr := flag.Bool("nn", false, "no format, don't format the numerical colums right adjusted")
 	nhlPtr := flag.Bool("nhl", false, "no headline, The data contains no headline")
@@ -92,6 +380,13 @@ func EvalFlags() {
 	ppPtr := flag.Bool("pp", false, "PrettyPrint, draw cell borders and all separators")
 	rhPtr := flag.Bool("rh", false, "RemoveHeader, removes the first line")
 	mbPtr := flag.Bool("mb", false, "MoreBlanks, more than one blank to split columns")
+	icsvPtr := flag.Bool("icsv", false, "InputCSV, parse the input as RFC 4180 CSV, -sep changes the delimiter, default=','")
+	itsvPtr := flag.Bool("itsv", false, "InputTSV, parse the input as tab separated values with CSV quoting")
+	ijsonPtr := flag.Bool("ijson", false, "InputJSON, parse the input as JSON array of objects or newline delimited JSON objects")
+	ilogfmtPtr := flag.Bool("ilogfmt", false, "InputLogfmt, parse the input lines as logfmt key=value pairs")
+	istanzaPtr := flag.Bool("istanza", false, "InputStanza, parse blocks of 'Key: value' lines separated by blank lines, each block is a row")
+	autoPtr := flag.Bool("auto", false, "AutoDetect, detect the input format and separator from the first lines of input")
+	fixedPtr := flag.Bool("fixed", false, "FixedWidth, split the input columns at the start positions of the fields in the headline")
 	numPtr := flag.Bool("num", false, "Num-bering, insert col numbers in the first line")
 	csvPtr := flag.Bool("csv", false, "CSV, write output in CSV format")
 	jsnPtr := flag.Bool("json", false, "JSON, write output in JSON format")
@@ -101,16 +396,45 @@ func EvalFlags() {
 	verPtr := flag.Bool("version", false, "Version, print version and exit")
 	verifyPtr := flag.Bool("v", false, "Verify, print parameter verirfy info")
 
-	flag.Parse()
+	flag.CommandLine.Parse(ColumnArgs(flag.CommandLine, os.Args[1:]))
+	flag.Visit(func(f *flag.Flag) { explicitFlags[f.Name] = true })
+
+	columns, colSpecs := getArgsColNumbers()
 
 	// define map with all flags
 	flags := T_flags{
-		Filename:   string(*filenmPtr),
+		Files:      files,
+		FileCol:    bool(*fcolPtr),
+		LineCol:    bool(*lcolPtr),
+		Union:      bool(*unionPtr),
+		Follow:     bool(*followPtr),
+		Stream:     bool(*streamPtr),
+		Sample:     int(*samplePtr),
+		Jobs:       int(*jobsPtr),
+		SortMem:    int(*sortmemPtr),
+		HeadEvery:  int(*heveryPtr),
+		HeadWidth:  bool(*hwidthPtr),
+		Strict:     bool(*strictPtr),
+		Ragged:     string(*raggedPtr),
+		Where:      string(*wherePtr),
+		Lead:       int(*leadPtr),
+		Rename:     string(*renamePtr),
+		Count:      bool(*countPtr),
+		Watch:      time.Duration(*watchPtr),
+		Key:        T_ColNum(*keyPtr),
+		Command:    watchCommand(),
+		Null:       string(*nullPtr),
 		Header:     string(*headerPtr),
 		Sep:        string(*sepPtr),
 		Colsep:     string(*colsepPtr),
-		Filter:     string(*filterPtr),
+		Filter:     filters,
+		FilterInv:  bool(*fvPtr),
+		FilterCase: bool(*fiPtr),
+		After:      int(*afterPtr),
+		Before:     int(*beforePtr),
 		Mark:       string(*markPtr),
+		Pattern:    string(*patternPtr),
+		NoMatch:    string(*nomatchPtr),
 		Gcol:       T_ColNum(*gcolnrPtr),
 		GcolVal:    bool(*gcolvalPtr),
 		SortCol:    T_ColNum(*sortColPtr),
@@ -131,8 +455,26 @@ func EvalFlags() {
 		Manual:     bool(*manPtr),
 		Version:    bool(*verPtr),
 		MoreBlanks: bool(*mbPtr),
-		verify:     bool(*verifyPtr),
-		Columns:    getArgsColNumbers(),
+		Fixed:      bool(*fixedPtr),
+		Icsv:       bool(*icsvPtr),
+		Itsv:       bool(*itsvPtr),
+		Ijson:      bool(*ijsonPtr),
+		Jarr:       string(*jarrPtr),
+		Ilogfmt:    bool(*ilogfmtPtr),
+		Istanza:    bool(*istanzaPtr),
+		Auto:       bool(*autoPtr),
+		Verify:     bool(*verifyPtr),
+		Columns:    columns,
+		ColSpecs:   colSpecs,
+		Widths:     getWidths(*widthsPtr),
+	}
+
+	// -A and -B win over -C like in grep
+	if !explicitFlags["A"] {
+		flags.After = int(*contextPtr)
+	}
+	if !explicitFlags["B"] {
+		flags.Before = int(*contextPtr)
 	}
 
 	CmdParams = flags
@@ -149,7 +491,7 @@ func EvalFlags() {
 		cmdExamples()
 	}
 
-	if flags.verify {
+	if flags.Verify {
 		println("\nCurrent values of parameters: ---------------------------------------")
 		flags.Print()
 	}
@@ -0,0 +1,183 @@
+package pc
+
+import (
+	ap "pc/argparse"
+	"strings"
+)
+
+// T_colkind is the type of the values of a column
+type T_colkind int
+
+const (
+	KindEmpty   T_colkind = iota // the column has only empty values
+	KindNumeric                  // all non empty values are numbers like 42, 1,5 or 3Mi
+	KindText                     // at least one value is not a number
+)
+
+// T_colstats are statistics of the values of a column
+type T_colstats struct {
+	Width    int // display width of the widest line of a value
+	Empty    int // number of empty values
+	Numeric  int // number of values with a line, that is a number
+	Distinct int // number of different values
+}
+
+// T_coldata holds the values of one column. Width and statistics are computed on first use and cached.
+type T_coldata struct {
+	Values   []string
+	scanned  bool
+	stats    T_colstats
+	distinct bool // stats.Distinct is counted
+}
+
+// T_colstore is the column oriented form of T_parsedData: one slice of values per column.
+// All columns have Rows values, the fields missing in ragged rows are empty. The original
+// length of every row is kept, so ToRows returns the ragged rows again.
+type T_colstore struct {
+	Cols []*T_coldata
+	Rows int
+	lens []int // length of the rows, nil if all rows have all columns
+}
+
+// ToColumns converts rows into the column oriented form
+func ToColumns(data T_parsedData) *T_colstore {
+	ncols := 0
+	for _, row := range data {
+		ncols = max(ncols, len(row))
+	}
+	s := &T_colstore{Cols: make([]*T_coldata, ncols), Rows: len(data)}
+	for c := range s.Cols {
+		s.Cols[c] = &T_coldata{Values: make([]string, len(data))}
+	}
+	for r, row := range data {
+		for c, val := range row {
+			s.Cols[c].Values[r] = val
+		}
+		if len(row) < ncols && s.lens == nil {
+			s.lens = make([]int, len(data))
+			for i := range data[:r] {
+				s.lens[i] = len(data[i])
+			}
+		}
+		if s.lens != nil {
+			s.lens[r] = len(row)
+		}
+	}
+	return s
+}
+
+// ToRows converts the columns back into rows
+func (s *T_colstore) ToRows() T_parsedData {
+	data := make(T_parsedData, s.Rows)
+	for r := range data {
+		n := len(s.Cols)
+		if s.lens != nil {
+			n = s.lens[r]
+		}
+		row := make(T_dataline, n)
+		for c := range row {
+			row[c] = s.Cols[c].Values[r]
+		}
+		data[r] = row
+	}
+	return data
+}
+
+// Select returns a store with the columns in the given order, the numbers start with 1.
+// The values are not copied. A number without column selects an empty column.
+func (s *T_colstore) Select(cols ap.T_ColNumbers) *T_colstore {
+	ns := &T_colstore{Cols: make([]*T_coldata, len(cols)), Rows: s.Rows}
+	var empty *T_coldata
+	for i, col := range cols {
+		if int(col) > 0 && int(col) <= len(s.Cols) {
+			ns.Cols[i] = s.Cols[col-1]
+			continue
+		}
+		if empty == nil {
+			empty = &T_coldata{Values: make([]string, s.Rows)}
+		}
+		ns.Cols[i] = empty
+	}
+	return ns
+}
+
+// scan computes width and types of the values once
+func (c *T_coldata) scan() {
+	if c.scanned {
+		return
+	}
+	for _, val := range c.Values {
+		switch {
+		case val == "":
+			c.stats.Empty++
+		case hasNumericLine(val):
+			c.stats.Numeric++
+		}
+		for {
+			l, rest, more := strings.Cut(val, "\n")
+			c.stats.Width = max(c.stats.Width, displayWidth(l))
+			if !more {
+				break
+			}
+			val = rest
+		}
+	}
+	c.scanned = true
+}
+
+// Width returns the display width of the widest line of a value
+func (c *T_coldata) Width() int {
+	c.scan()
+	return c.stats.Width
+}
+
+// Stats returns the statistics of the column, they are computed only once
+func (c *T_coldata) Stats() T_colstats {
+	c.scan()
+	if !c.distinct {
+		values := map[string]bool{}
+		for _, val := range c.Values {
+			values[val] = true
+		}
+		c.stats.Distinct = len(values)
+		c.distinct = true
+	}
+	return c.stats
+}
+
+// Kind returns the type of the values of the column
+func (c *T_coldata) Kind() T_colkind {
+	c.scan()
+	switch {
+	case c.stats.Empty == len(c.Values):
+		return KindEmpty
+	case c.stats.Numeric+c.stats.Empty == len(c.Values):
+		return KindNumeric
+	}
+	return KindText
+}
+
+// Widths returns the display width of every column like GetMaxLength does for rows
+func (s *T_colstore) Widths() T_maxlenghts {
+	widths := make(T_maxlenghts, len(s.Cols))
+	for i, c := range s.Cols {
+		widths[i] = c.Width()
+	}
+	return widths
+}
+
+// Layout returns the output layout of the columns: their widths and, unless -nn is set,
+// if they contain numbers, that are right adjusted.
+func (s *T_colstore) Layout() T_columns {
+	return s.layout(!ap.CmdParams.Nn)
+}
+
+// layout returns the output layout of the columns, with numbers false no column is numeric
+func (s *T_colstore) layout(numbers bool) T_columns {
+	cols := make(T_columns, len(s.Cols))
+	for i, c := range s.Cols {
+		c.scan()
+		cols[i] = T_column{Width: c.stats.Width, Numeric: c.stats.Numeric > 0 && numbers}
+	}
+	return cols
+}
@@ -0,0 +1,120 @@
+package pc
+
+import (
+	ap "pc/argparse"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// T_column holds the layout of an output column, computed once before the rows are formatted
+type T_column struct {
+	Width   int  // display width of the column
+	Numeric bool // numbers in this column are right adjusted
+}
+
+// T_columns is the layout of all output columns
+type T_columns []T_column
+
+// NewColumns returns the column layout for the widths in maxlen. A column is only numeric,
+// if it has a value, that looks like a number, and -nn is not set. Without data every
+// column may contain numbers.
+func NewColumns(data T_parsedData, maxlen T_maxlenghts) T_columns {
+	cols := make(T_columns, len(maxlen))
+	for i, w := range maxlen {
+		cols[i].Width = w
+	}
+	if ap.CmdParams.Nn {
+		return cols
+	}
+	if data == nil {
+		for i := range cols {
+			cols[i].Numeric = true
+		}
+		return cols
+	}
+	for _, row := range data {
+		for i, val := range row {
+			if i < len(cols) && !cols[i].Numeric {
+				cols[i].Numeric = hasNumericLine(val)
+			}
+		}
+	}
+	return cols
+}
+
+// hasNumericLine returns true, if one of the lines of a cell is a number
+func hasNumericLine(val string) bool {
+	for {
+		l, rest, more := strings.Cut(val, "\n")
+		if isNumeric(l) {
+			return true
+		}
+		if !more {
+			return false
+		}
+		val = rest
+	}
+}
+
+// numericUnits are the unit suffixes allowed behind a number, the two letter units first
+var numericUnits = []string{"Ki", "Mi", "Gi", "k", "m", "d", "h", "H", "M", "J", "Y"}
+
+// isNumeric returns true, if the text is a number of digits, dots and commas with an optional
+// unit like 5k, 3d or 12Mi, surrounded by optional blanks. It matches the same texts as the
+// regex ^ *[0-9\.,]+(k|m|d|h|H|M|J|Y|Ki|Mi|Gi){0,1} *$ without its cost.
+func isNumeric(s string) bool {
+	s = strings.Trim(s, " ")
+	for _, unit := range numericUnits {
+		if strings.HasSuffix(s, unit) {
+			s = s[:len(s)-len(unit)]
+			break
+		}
+	}
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && s[i] != '.' && s[i] != ',' {
+			return false
+		}
+	}
+	return true
+}
+
+// blanks is sliced for the padding of cells, longer paddings are built by strings.Repeat
+var blanks = strings.Repeat(" ", 256)
+
+// padding returns n blanks
+func padding(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n <= len(blanks) {
+		return blanks[:n]
+	}
+	return strings.Repeat(" ", n)
+}
+
+// displayWidth returns the display width of the text. Printable ASCII text is counted
+// directly, other text by runewidth.
+func displayWidth(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return runewidth.StringWidth(s)
+		}
+	}
+	return len(s)
+}
+
+// padCell pads one line of a cell to the width, numbers of numeric columns are right adjusted
+func padCell(l string, col T_column) string {
+	fill := col.Width - displayWidth(l)
+	if fill <= 0 {
+		return l
+	}
+	if col.Numeric && isNumeric(l) {
+		return padding(fill) + l
+	}
+	return l + padding(fill)
+}
@@ -0,0 +1,51 @@
+package pc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	ap "pc/argparse"
+	"strings"
+)
+
+// byteOrderMark is the UTF-8 BOM, that spreadsheet programs put in front of exported CSV files
+const byteOrderMark = "\uFEFF"
+
+// CsvParse parses the raw lines as RFC 4180 records with comma as field delimiter.
+// Quoted fields may contain the delimiter, linefeeds and doubled "" quotes, the quotes
+// are removed from the values. A leading BOM is dropped.
+// The raw lines are joined again, so records spanning several lines are read as one record.
+func CsvParse(data T_rawdata, comma rune) T_parsedData {
+	pdata, err := csvParse(data, comma)
+	if err != nil {
+		ap.Fail(err)
+	}
+	return pdata
+}
+
+// csvParse parses the raw lines as CSV records. The first malformed record is returned as
+// error with its line and column.
+func csvParse(data T_rawdata, comma rune) (T_parsedData, error) {
+	pdata := T_parsedData{}
+	text := strings.TrimPrefix(strings.Join(data, "\n"), byteOrderMark)
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, ap.ErrorAt(ap.ErrData, fmt.Errorf("error reading csv record: %w", pe.Err), "", pe.Line, pe.Column)
+		}
+		if err != nil {
+			return nil, ap.ErrorAt(ap.ErrData, fmt.Errorf("error reading csv record: %w", err), "", 0, 0)
+		}
+		pdata = append(pdata, T_dataline(record))
+	}
+	return pdata, nil
+}
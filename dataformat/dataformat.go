@@ -1,10 +1,12 @@
 package pc
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	ap "pc/argparse"
 	"regexp"
@@ -13,7 +15,6 @@ import (
 	"strings"
 
 	pluralize "github.com/gertd/go-pluralize"
-	"github.com/mattn/go-runewidth"
 	"golang.org/x/exp/slices"
 )
 
@@ -26,104 +27,169 @@ type T_dataline []string
 // T_parsedData represents the entire parsed dataset as a slice of T_dataline
 type T_parsedData []T_dataline
 
+// jsonKey returns the title of the column in the header as key for the JSON output.
+// A column without title gets its number as key, with -strict it is an error.
+func jsonKey(header T_dataline, col int, p *ap.T_flags) (string, error) {
+	if col < len(header) {
+		return header[col], nil
+	}
+	if p.Strict {
+		return "", ap.NewError(ap.ErrData, "column %d has no title in the headline %q", col+1, strings.Join(header, " "))
+	}
+	return strconv.Itoa(col + 1), nil
+}
+
+// headerLine returns the -header line with the titles of -fcol and -lcol, the selected columns
+// and the titles of -rename
+func headerLine(p *ap.T_flags, sep rune) (T_dataline, error) {
+	headerline := headerTitles(p, sep)
+	if len(p.Columns) > 0 && len(headerline) > len(p.Columns) {
+		headerline.selectColumns(p.Columns)
+	}
+	return headerline, ap.RenameColumns(headerline, p.Rename)
+}
+
 // printJSON prints the parsed data in JSON format.
-// It uses the header line defined in CmdParams.Header and the separator defined in CmdParams.Sep.
-func printJSON(d T_parsedData) {
-	sep := []rune(ap.CmdParams.Sep)[0]
-	header := LineParse(ap.CmdParams.Header, sep)
+// It uses the header line defined in p.Header and the separator defined in p.Sep.
+func printJSON(w io.Writer, d T_parsedData, p *ap.T_flags) error {
+	header, err := headerLine(p, []rune(p.Sep)[0])
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("[")
+	fmt.Fprintln(w, "[")
 	for ln, line := range d {
-		fmt.Println("  {")
+		fmt.Fprintln(w, "  {")
 		for col, val := range line {
 			// Print each key-value pair
-			fmt.Printf("    %q: %q", header[col], val)
+			key, err := jsonKey(header, col, p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "    %q: %q", key, val)
 			if col+1 < len(line) {
-				fmt.Println(",")
+				fmt.Fprintln(w, ",")
 			} else {
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
 		}
 		// Close the object and add a comma if it's not the last line
 		if ln+1 < len(d) {
-			fmt.Println("  },")
+			fmt.Fprintln(w, "  },")
 		} else {
-			fmt.Println("  }")
+			fmt.Fprintln(w, "  }")
 		}
 	}
-	fmt.Println("]")
+	fmt.Fprintln(w, "]")
+	return nil
 }
 
 // printJSONwithTC prints the parsed data in JSON format with a top-level collection.
-// It uses the header line defined in CmdParams.Header and the separator defined in CmdParams.Sep.
-func printJSONwithTC(d T_parsedData) {
-	sep := []rune(ap.CmdParams.Sep)[0]
-	header := d[0]
-	if ap.CmdParams.Header != "" {
-		header = LineParse(ap.CmdParams.Header, sep)
-	}
-	if ap.CmdParams.Ts {
+// It uses the header line defined in p.Header and the separator defined in p.Sep.
+func printJSONwithTC(w io.Writer, d T_parsedData, p *ap.T_flags) error {
+	sep := []rune(p.Sep)[0]
+	header := T_dataline{}
+	if len(d) > 0 {
+		header = d[0]
+	}
+	if p.Header != "" {
+		var err error
+		if header, err = headerLine(p, sep); err != nil {
+			return err
+		}
+	}
+	if p.Ts && len(d) > 0 {
 		d = d[1:]
 	}
 
-	hkey := header[0]
-	header = header[1:]
+	hkey, err := jsonKey(header, 0, p)
+	if err != nil {
+		return err
+	}
 	pl := pluralize.NewClient()
 
 	// Print the opening of the top-level collection
-	fmt.Printf("{\n  %q: [\n", pl.Plural(hkey))
+	fmt.Fprintf(w, "{\n  %q: [\n", pl.Plural(hkey))
 	for ln, line := range d {
+		if len(line) == 0 {
+			line = T_dataline{""}
+		}
 		// Print each object in the collection
-		fmt.Printf("    {\n      %q: %q,\n      \"data\": {\n", hkey, line[0])
+		fmt.Fprintf(w, "    {\n      %q: %q,\n      \"data\": {\n", hkey, line[0])
 		for col, val := range line[1:] {
-			fmt.Printf("        %q: %q", header[col], val)
+			key, err := jsonKey(header, col+1, p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "        %q: %q", key, val)
 			if col+1 < len(line)-1 {
-				fmt.Println(",")
+				fmt.Fprintln(w, ",")
 			} else {
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
 		}
-		fmt.Println("      }")
+		fmt.Fprintln(w, "      }")
 		if ln+1 < len(d) {
-			fmt.Println("    },")
+			fmt.Fprintln(w, "    },")
 		} else {
-			fmt.Println("    }")
+			fmt.Fprintln(w, "    }")
 		}
 	}
 	// Close the top-level collection
-	fmt.Println("  ]\n}")
+	fmt.Fprintln(w, "  ]\n}")
+	return nil
 }
 
-// PrintJson prints the parsed data in JSON format.
-// It chooses between direct JSON marshaling, printJSONwithTC, or printJSON based on CmdParams flags.
+// PrintJson prints the parsed data in JSON format to STDOUT, see WriteJson.
 func (d T_parsedData) PrintJson() {
+	if err := d.WriteJson(os.Stdout, &ap.CmdParams); err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrOutput, err, "", 0, 0))
+	}
+}
+
+// WriteJson writes the parsed data in JSON format to w.
+// It chooses between direct JSON marshaling, printJSONwithTC, or printJSON based on the flags in p.
+func (d T_parsedData) WriteJson(w io.Writer, p *ap.T_flags) error {
+	bw := bufio.NewWriter(w)
 	// Try direct JSON marshaling if no header is specified and Ts flag is not set
-	if ap.CmdParams.Header == "" && !ap.CmdParams.Ts {
+	if p.Header == "" && !p.Ts {
 		b, err := json.MarshalIndent(d, "", "  ")
 		if err == nil {
-			fmt.Println(string(b))
-			return
+			bw.Write(b)
+			bw.WriteByte('\n')
+			return bw.Flush()
 		}
 	}
 
 	// Choose between printJSONwithTC and printJSON based on flags
-	if ap.CmdParams.Jtc || ap.CmdParams.Ts {
-		printJSONwithTC(d)
-	} else {
-		printJSON(d)
+	print := printJSON
+	if p.Jtc || p.Ts {
+		print = printJSONwithTC
+	}
+	if err := print(bw, d, p); err != nil {
+		return err
 	}
+	return bw.Flush()
 }
 
-// PrintCsv prints the parsed data in CSV format.
-// It writes the data to the standard output using the csv.Writer from the encoding/csv package.
+// PrintCsv prints the parsed data in CSV format to STDOUT, see WriteCsv.
 func (d T_parsedData) PrintCsv() {
-	w := csv.NewWriter(os.Stdout)
-	defer w.Flush()
+	if err := d.WriteCsv(os.Stdout); err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrOutput, err, "", 0, 0))
+	}
+}
+
+// WriteCsv writes the parsed data in CSV format to w.
+// It uses the csv.Writer from the encoding/csv package.
+func (d T_parsedData) WriteCsv(w io.Writer) error {
+	cw := csv.NewWriter(w)
 	for _, record := range d {
-		if err := w.Write(record); err != nil {
-			log.Fatalln("error writing record", err)
+		if err := cw.Write(record); err != nil {
+			return err
 		}
 	}
+	cw.Flush()
+	return cw.Error()
 }
 
 // Append appends a dataline to the parsed data
@@ -134,37 +200,28 @@ func (d *T_parsedData) Append(l T_dataline) {
 // Insert inserts a dataline at the given position in the parsed data
 func (d *T_parsedData) Insert(l T_dataline, pos int) {
 	if pos >= 0 && pos <= len(*d) {
-		*d = append(*d, l)
-		data := *d
-		copy(data[(pos+1):], data[pos:])
-		data[pos] = l
-		d = &data
+		*d = slices.Insert(*d, pos, l)
 	}
 }
 
-// generateLine formats the fields of dataline to maxlen of columns
-func (data *T_dataline) generateLine(maxlen T_maxlenghts) {
-	for pos, mxlen := range maxlen {
-		val := ""
-		if pos < len(*data) {
-			val = (*data)[pos]
-		} else {
-			*data = append(*data, strings.Repeat(" ", mxlen))
+// GenerateLine pads the fields of dataline to the widths of the columns.
+// Numbers in numeric columns are right adjusted, missing fields are filled with blanks.
+func (data *T_dataline) GenerateLine(cols T_columns) {
+	for pos, col := range cols {
+		if pos >= len(*data) {
+			*data = append(*data, padding(col.Width))
+			continue
+		}
+		val := (*data)[pos]
+		if !strings.Contains(val, "\n") {
+			(*data)[pos] = padCell(val, col)
 			continue
 		}
-
 		lines := strings.Split(val, "\n")
-		var paddedLines []string
-		for _, l := range lines {
-			displayWidth := runewidth.StringWidth(l)
-			blanklen := mxlen - displayWidth
-			if regexp.MustCompile(`^ *[0-9\.,]+(k|m|d|h|H|M|J|Y|Ki|Mi|Gi){0,1} *$`).MatchString(l) && !ap.CmdParams.Nn {
-				paddedLines = append(paddedLines, strings.Repeat(" ", blanklen)+l)
-			} else {
-				paddedLines = append(paddedLines, l+strings.Repeat(" ", blanklen))
-			}
+		for i, l := range lines {
+			lines[i] = padCell(l, col)
 		}
-		(*data)[pos] = strings.Join(paddedLines, "\n")
+		(*data)[pos] = strings.Join(lines, "\n")
 	}
 }
 
@@ -175,7 +232,7 @@ func (data *T_parsedData) InsertGroupSeperator(gcol int, gcolval bool, trenner,
 	}
 
 	gcol--
-	nd := T_parsedData{}
+	nd := make(T_parsedData, 0, len(*data))
 	ref := ""
 
 	for i, row := range *data {
@@ -198,31 +255,21 @@ func (data *T_parsedData) InsertGroupSeperator(gcol int, gcolval bool, trenner,
 	*data = nd
 }
 
-// selectColumns selects data columns as defined in CmdParams.Columns.
-// It iterates over each row in the parsed data and creates a new row containing only the selected columns.
-// If a column index is out of range, it adds an empty string to the new row.
-func (data *T_parsedData) selectColumns() {
-	if len(ap.CmdParams.Columns) > 0 {
-		for i, row := range *data {
-			nrow := T_dataline{}
-			for _, col := range ap.CmdParams.Columns {
-				if int(col) > 0 && int(col) <= len(row) {
-					nrow = append(nrow, row[col-1])
-				} else {
-					nrow = append(nrow, "")
-				}
-			}
-			(*data)[i] = nrow
-		}
+// selectColumns selects data columns as defined by cols (-columns).
+// The selection is a reorder of the columns of the column oriented form of the data.
+// If a column index is out of range, the rows get an empty string for it.
+func (data *T_parsedData) selectColumns(cols ap.T_ColNumbers) {
+	if len(cols) > 0 {
+		*data = ToColumns(*data).Select(cols).ToRows()
 	}
 }
 
-// selectColumns selects columns from dataline as defined in CmdParams.Columns.
+// selectColumns selects columns from dataline as defined by cols (-columns).
 // It creates a new dataline containing only the selected columns.
 // If a column index is out of range, it adds an empty string to the new dataline.
-func (data *T_dataline) selectColumns() {
+func (data *T_dataline) selectColumns(cols ap.T_ColNumbers) {
 	nrow := T_dataline{}
-	for _, col := range ap.CmdParams.Columns {
+	for _, col := range cols {
 		if int(col) > 0 && int(col) <= len(*data) {
 			nrow = append(nrow, (*data)[col-1])
 		} else {
@@ -232,21 +279,35 @@ func (data *T_dataline) selectColumns() {
 	*data = nrow
 }
 
+// resolveColumns sets the selected columns from the column arguments, that need the titles of
+// the headline, for a table of width columns. The arguments are resolved only once.
+func resolveColumns(p *ap.T_flags, header T_dataline, width int) error {
+	if len(p.ColSpecs) == 0 {
+		return nil
+	}
+	cols, err := ap.ResolveColumns(p.ColSpecs, header, max(width, len(header)))
+	if err != nil {
+		return err
+	}
+	p.Columns, p.ColSpecs = cols, nil
+	return nil
+}
+
 // insertTrenner inserts separators for TitleSeparator, FooterSeparator, or PrettyPrint.
-func (data *T_parsedData) insertTrenner(trenner, htrenner []string) {
-	if ap.CmdParams.Ts || ap.CmdParams.Fs || ap.CmdParams.Pp {
-		if ap.CmdParams.Pp {
-			if ap.CmdParams.Fs {
+func (data *T_parsedData) insertTrenner(p *ap.T_flags, trenner, htrenner []string) {
+	if p.Ts || p.Fs || p.Pp {
+		if p.Pp {
+			if p.Fs {
 				data.Insert(htrenner, len(*data)-1)
 			}
 			data.Insert(trenner, 0)
 			data.Insert(trenner, 2)
 			data.Append(trenner)
 		} else {
-			if ap.CmdParams.Ts {
+			if p.Ts {
 				data.Insert(htrenner, 1)
 			}
-			if ap.CmdParams.Fs {
+			if p.Fs {
 				data.Insert(htrenner, len(*data)-1)
 			}
 		}
@@ -254,24 +315,42 @@ func (data *T_parsedData) insertTrenner(trenner, htrenner []string) {
 }
 
 // sort sorts the parsed data based on the specified column index.
-func (data *T_parsedData) sort(k int) {
+// Rows without the column sort first, with -strict they are an error.
+func (data *T_parsedData) sort(p *ap.T_flags, k int) error {
 	k--
 	l1 := T_dataline{}
 	d := *data
+	if len(d) == 0 {
+		return nil
+	}
 
-	if !ap.CmdParams.Nhl {
+	if !p.Nhl {
 		l1, d = d[0], d[1:]
 	}
 
+	cell := func(row T_dataline) string {
+		if k < len(row) {
+			return row[k]
+		}
+		return ""
+	}
+	if p.Strict {
+		for i, row := range d {
+			if k >= len(row) {
+				return ap.NewError(ap.ErrData, "row %d %q has no column %d to sort by", i+1, strings.Join(row, " "), k+1)
+			}
+		}
+	}
 	sort.SliceStable(d, func(i, j int) bool {
-		return d[i][k] < d[j][k]
+		return cell(d[i]) < cell(d[j])
 	})
 
-	if !ap.CmdParams.Nhl {
+	if !p.Nhl {
 		*data = append(T_parsedData{l1}, d...)
 	} else {
 		*data = d
 	}
+	return nil
 }
 
 // delete elements from data
@@ -279,114 +358,180 @@ func (data *T_parsedData) delete(i, j int) {
 	*data = slices.Delete(*data, i, j)
 }
 
-// formatDataToMaxWidth formats the data to column max width
-func (data *T_parsedData) formatDataToMaxWidth(maxlen []int) {
+// formatDataToMaxWidth formats the data to the column widths
+func (data *T_parsedData) formatDataToMaxWidth(cols T_columns) {
 	for i := range *data {
-		(*data)[i].generateLine(maxlen)
+		(*data)[i].GenerateLine(cols)
 	}
 }
 
-// printAsciiTab prints the parsed data as an ASCII table.
+// PrintAsciiTab prints the parsed data as an ASCII table to STDOUT, see WriteAsciiTab.
+func (data *T_parsedData) PrintAsciiTab(maxlen T_maxlenghts) {
+	if err := data.WriteAsciiTab(os.Stdout, &ap.CmdParams, maxlen); err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrOutput, err, "", 0, 0))
+	}
+}
+
+// WriteAsciiTab writes the parsed data as an ASCII table to w.
 // It formats each row of the data according to the specified column separator and column width.
 // If a field contains linefeeds, the cell is printed across multiple visual lines and
 // all sibling cells are padded with blanks for those extra lines.
 // If the PrettyPrint (Pp) or ColumnSeparator (Cs) flags are set, it adds the column separator between columns.
 // If the MoreBlanks flag is set, it replaces the placeholder character '§' with spaces.
-func (data *T_parsedData) printAsciiTab(maxlen T_maxlenghts) {
-	sp := strings.Repeat(" ", ap.CmdParams.ColSepW)
-	numCols := len(maxlen)
+// Lines matching the Mark regex are colored. The output is written through one buffered writer.
+func (data *T_parsedData) WriteAsciiTab(w io.Writer, p *ap.T_flags, maxlen T_maxlenghts) error {
 	var markRe *regexp.Regexp
-	if ap.CmdParams.Mark != "" {
+	if p.Mark != "" {
 		var err error
-		markRe, err = regexp.Compile(ap.CmdParams.Mark)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Invalid -mark regex: %v\n", err)
+		if markRe, err = regexp.Compile(p.Mark); err != nil {
+			return ap.NewError(ap.ErrParam, "-mark='%s' must be a valid regex. %v", p.Mark, err)
 		}
 	}
+	bw := bufio.NewWriterSize(w, 64*1024)
+	sp := padding(p.ColSepW)
+	inner := sp + p.Colsep + sp
+	if !(p.Pp || p.Cs) {
+		inner = sp
+	}
+	numCols := len(maxlen)
+	var line []byte
+	subLines := [][]string{}
 	for _, row := range *data {
-		// Split each field into sub-lines
-		subLines := make([][]string, len(row))
-		maxSubLines := 0
-		for i, field := range row {
-			subLines[i] = strings.Split(field, "\n")
-			if len(subLines[i]) > maxSubLines {
-				maxSubLines = len(subLines[i])
+		// Split only the fields with linefeeds into sub-lines
+		subLines = subLines[:0]
+		maxSubLines := 1
+		for _, field := range row {
+			var sub []string
+			if strings.Contains(field, "\n") {
+				sub = strings.Split(field, "\n")
+				maxSubLines = max(maxSubLines, len(sub))
 			}
-		}
-		if maxSubLines == 0 {
-			maxSubLines = 1
+			subLines = append(subLines, sub)
 		}
 
 		// Print one visual line at a time
 		for lineIdx := 0; lineIdx < maxSubLines; lineIdx++ {
-			var parts []string
+			line = line[:0]
+			if p.Pp || p.Cs {
+				line = append(line, p.Colsep+sp...)
+			}
 			for col := 0; col < numCols; col++ {
-				if col < len(subLines) && lineIdx < len(subLines[col]) {
-					parts = append(parts, subLines[col][lineIdx])
-				} else {
-					parts = append(parts, strings.Repeat(" ", maxlen[col]))
+				if col > 0 {
+					line = append(line, inner...)
+				}
+				switch {
+				case col < len(row) && subLines[col] == nil && lineIdx == 0:
+					line = append(line, row[col]...)
+				case col < len(row) && lineIdx < len(subLines[col]):
+					line = append(line, subLines[col][lineIdx]...)
+				default:
+					line = append(line, padding(maxlen[col])...)
 				}
 			}
-
-			var line string
-			if ap.CmdParams.Pp || ap.CmdParams.Cs {
-				line = ap.CmdParams.Colsep + sp + strings.Join(parts, sp+ap.CmdParams.Colsep+sp) + sp + ap.CmdParams.Colsep
-			} else {
-				line = strings.Join(parts, sp)
+			if p.Pp || p.Cs {
+				line = append(line, sp+p.Colsep...)
 			}
-			if ap.CmdParams.MoreBlanks {
-				line = strings.Replace(line, "§", " ", -1)
+			if p.MoreBlanks {
+				line = bytes.ReplaceAll(line, []byte("§"), []byte(" "))
 			}
 			// Apply color if line matches regex
-			if markRe != nil && markRe.MatchString(line) {
+			if markRe != nil && markRe.Match(line) {
 				// ANSI escape code for yellow
-				line = "\033[33m" + line + "\033[0m"
+				bw.WriteString("\033[33m")
+				bw.Write(line)
+				bw.WriteString("\033[0m\n")
+				continue
 			}
-			fmt.Println(line)
+			bw.Write(line)
+			bw.WriteByte('\n')
 		}
 	}
+	return bw.Flush()
 }
 
-// Format selects the data and header columns, inserts separators, formats the data fields,
-// and prints the data as CSV, JSON, or ASCII table depending on options.
-func Format(data T_parsedData) {
-	sep := []rune(ap.CmdParams.Sep)[0]
-
+// arrange selects the data and header columns, removes the header, sorts the rows
+// and inserts the -header line, as defined by the options.
+func (data *T_parsedData) arrange(p *ap.T_flags) error {
+	sep := []rune(p.Sep)[0]
+
+	// Resolve the column titles, regexes and exclusions against the headline or -header
+	if len(p.ColSpecs) > 0 {
+		var header T_dataline
+		if p.Header != "" {
+			header = headerTitles(p, sep)
+		} else if !p.Nhl && len(*data) > 0 {
+			header = (*data)[0]
+		}
+		width := 0
+		for _, row := range *data {
+			width = max(width, len(row))
+		}
+		if err := resolveColumns(p, header, width); err != nil {
+			return err
+		}
+	}
 	// Apply column selection if specified
-	if len(ap.CmdParams.Columns) > 0 {
-		data.selectColumns()
+	if len(p.Columns) > 0 {
+		data.selectColumns(p.Columns)
+	}
+	// Rename the titles of the headline, the -header line is renamed when it is inserted
+	if p.Rename != "" && p.Header == "" && !p.Nhl && len(*data) > 0 {
+		if err := ap.RenameColumns((*data)[0], p.Rename); err != nil {
+			return err
+		}
 	}
 	// Remove header if Rh flag is set
-	if ap.CmdParams.Rh {
+	if p.Rh {
 		data.delete(0, 1)
 	}
 	// Sort data if SortCol is specified
-	if ap.CmdParams.SortCol > 0 {
-		data.sort(int(ap.CmdParams.SortCol))
+	if p.SortCol > 0 {
+		if err := data.sort(p, int(p.SortCol)); err != nil {
+			return err
+		}
 	}
 	// Insert header if specified and not in JSON mode
-	if ap.CmdParams.Header != "" && !ap.CmdParams.Json {
-		headerline := LineParse(ap.CmdParams.Header, sep)
-		if len(ap.CmdParams.Columns) > 0 && len(headerline) > len(ap.CmdParams.Columns) {
-			headerline.selectColumns()
+	if p.Header != "" && !p.Json {
+		headerline, err := headerLine(p, sep)
+		if err != nil {
+			return err
 		}
 		data.Insert(headerline, 0)
 	}
+	return nil
+}
 
-	// Calculate maximum length for each column
-	maxlen := GetMaxLength(data)
+// Format selects the data and header columns, inserts separators, formats the data fields,
+// and prints the data as CSV, JSON, or ASCII table to STDOUT depending on options.
+func Format(data T_parsedData) {
+	if err := FormatTo(os.Stdout, data, &ap.CmdParams); err != nil {
+		ap.Fail(err)
+	}
+}
+
+// FormatTo is Format with the options p instead of the command line parameters.
+// The output is written to w, errors are returned as ap.T_error.
+func FormatTo(w io.Writer, data T_parsedData, p *ap.T_flags) error {
+	if err := data.arrange(p); err != nil {
+		return err
+	}
+
+	// Calculate maximum length for each column, once in the column oriented form
+	store := ToColumns(data)
+	maxlen := store.Widths()
 	// Insert row numbers if Num flag is set
-	if ap.CmdParams.Num {
+	if p.Num {
 		n := make([]string, len(maxlen))
 		for i := range maxlen {
 			ns := strconv.Itoa(i + 1)
-			if len(ap.CmdParams.Columns) > 0 {
-				ns += fmt.Sprintf(" [%d]", ap.CmdParams.Columns[i])
+			if len(p.Columns) > 0 {
+				ns += fmt.Sprintf(" [%d]", p.Columns[i])
 			}
 			n[i] = ns
 		}
 		data.Insert(n, 0)
-		maxlen = GetMaxLength(data)
+		store = ToColumns(data)
+		maxlen = store.Widths()
 	}
 
 	// Create separator lines
@@ -398,21 +543,26 @@ func Format(data T_parsedData) {
 	}
 
 	// Insert separators if not in CSV or JSON mode
-	if !(ap.CmdParams.Json || ap.CmdParams.Csv) {
-		data.insertTrenner(trenner, htrenner)
+	if !(p.Json || p.Csv) {
+		data.insertTrenner(p, trenner, htrenner)
 	}
 
 	// Output data in the appropriate format
+	var err error
 	switch {
-	case ap.CmdParams.Csv:
-		data.PrintCsv()
-	case ap.CmdParams.Json:
-		data.PrintJson()
+	case p.Csv:
+		err = data.WriteCsv(w)
+	case p.Json:
+		err = data.WriteJson(w, p)
 	default:
-		data.InsertGroupSeperator(int(ap.CmdParams.Gcol), ap.CmdParams.GcolVal, trenner, htrenner)
-		if !ap.CmdParams.Nf {
-			data.formatDataToMaxWidth(maxlen)
+		data.InsertGroupSeperator(int(p.Gcol), p.GcolVal, trenner, htrenner)
+		if !p.Nf {
+			data.formatDataToMaxWidth(store.layout(!p.Nn))
 		}
-		data.printAsciiTab(maxlen)
+		err = data.WriteAsciiTab(w, p, maxlen)
+	}
+	if err != nil {
+		return ap.ErrorAt(ap.ErrOutput, err, "", 0, 0)
 	}
+	return nil
 }
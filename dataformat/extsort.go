@@ -0,0 +1,166 @@
+package pc
+
+import (
+	"container/heap"
+	"encoding/csv"
+	"io"
+	"os"
+	ap "pc/argparse"
+	"sort"
+)
+
+// t_extSorter sorts rows by one column. Rows are kept in memory up to a limit of bytes,
+// beyond that the sorted rows are spilled as a run to a temporary file. The runs are
+// merged, when the rows are read back. Equal values keep the input order.
+type t_extSorter struct {
+	col   int // index of the sort column
+	limit int // bytes of rows held in memory before they are spilled
+	size  int
+	rows  T_parsedData
+	runs  []*os.File
+}
+
+// newExtSorter returns a sorter for the column index, that spills rows beyond limit bytes
+func newExtSorter(col, limit int) *t_extSorter {
+	return &t_extSorter{col: col, limit: limit}
+}
+
+// cell returns the value of the sort column, rows without the column sort first
+func (s *t_extSorter) cell(row T_dataline) string {
+	if s.col < len(row) {
+		return row[s.col]
+	}
+	return ""
+}
+
+// rowSize estimates the memory used by a row
+func rowSize(row T_dataline) int {
+	size := 24
+	for _, c := range row {
+		size += len(c) + 16
+	}
+	return size
+}
+
+// add collects a row and spills the collected rows, if the memory limit is reached
+func (s *t_extSorter) add(row T_dataline) error {
+	s.rows = append(s.rows, row)
+	if s.size += rowSize(row); s.size >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+// sortRows sorts the rows in memory, equal values keep their order
+func (s *t_extSorter) sortRows() {
+	sort.SliceStable(s.rows, func(i, j int) bool {
+		return s.cell(s.rows[i]) < s.cell(s.rows[j])
+	})
+}
+
+// spill writes the sorted rows in memory as a run to a temporary file
+func (s *t_extSorter) spill() error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	s.sortRows()
+	file, err := os.CreateTemp("", "pc-sort-*.csv")
+	if err != nil {
+		return ap.NewError(ap.ErrOutput, "create temporary file for sorting: %w", err)
+	}
+	s.runs = append(s.runs, file)
+	w := csv.NewWriter(file)
+	for _, row := range s.rows {
+		// the leading field keeps rows without any value from being read as empty line
+		w.Write(append(T_dataline{"."}, row...))
+	}
+	if w.Flush(); w.Error() != nil {
+		return ap.ErrorAt(ap.ErrOutput, w.Error(), file.Name(), 0, 0)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return ap.ErrorAt(ap.ErrInput, err, file.Name(), 0, 0)
+	}
+	s.rows = nil
+	s.size = 0
+	return nil
+}
+
+// t_mergeItem is the next row of a run during the merge
+type t_mergeItem struct {
+	row T_dataline
+	run int
+}
+
+// t_mergeHeap holds the next row of every run, the smallest row on top
+type t_mergeHeap struct {
+	items []t_mergeItem
+	s     *t_extSorter
+}
+
+func (h t_mergeHeap) Len() int { return len(h.items) }
+func (h t_mergeHeap) Less(i, j int) bool {
+	a, b := h.s.cell(h.items[i].row), h.s.cell(h.items[j].row)
+	if a != b {
+		return a < b
+	}
+	return h.items[i].run < h.items[j].run // earlier runs hold the earlier rows
+}
+func (h t_mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *t_mergeHeap) Push(x any)   { h.items = append(h.items, x.(t_mergeItem)) }
+func (h *t_mergeHeap) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+// each calls fn for all rows in sorted order. Without spilled runs the rows are sorted in
+// memory, otherwise the runs are merged.
+func (s *t_extSorter) each(fn func(T_dataline)) error {
+	if len(s.runs) == 0 {
+		s.sortRows()
+		for _, row := range s.rows {
+			fn(row)
+		}
+		return nil
+	}
+	if err := s.spill(); err != nil {
+		return err
+	}
+	readers := make([]*csv.Reader, len(s.runs))
+	h := &t_mergeHeap{s: s}
+	next := func(run int) error {
+		row, err := readers[run].Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ap.ErrorAt(ap.ErrInput, err, s.runs[run].Name(), 0, 0)
+		}
+		heap.Push(h, t_mergeItem{row: row[1:], run: run})
+		return nil
+	}
+	for i, file := range s.runs {
+		readers[i] = csv.NewReader(file)
+		readers[i].FieldsPerRecord = -1
+		if err := next(i); err != nil {
+			return err
+		}
+	}
+	for h.Len() > 0 {
+		item := heap.Pop(h).(t_mergeItem)
+		fn(item.row)
+		if err := next(item.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close removes the temporary files
+func (s *t_extSorter) close() {
+	for _, file := range s.runs {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	s.runs = nil
+}
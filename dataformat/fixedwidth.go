@@ -0,0 +1,158 @@
+package pc
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// T_bounds holds the display-width start positions of the columns of fixed-width input.
+// The last column reaches to the end of the line.
+type T_bounds []int
+
+// tabWidth is the distance of the tab stops, when tabs are expanded in fixed-width input
+const tabWidth = 8
+
+// displayCells splits a line into its runes together with the display position each rune starts at.
+// Tabs are expanded to the next tab stop, wide runes occupy two display positions.
+func displayCells(line string) ([]rune, []int) {
+	runes := []rune{}
+	positions := []int{}
+	pos := 0
+	for _, r := range line {
+		if r == '\t' {
+			next := (pos/tabWidth + 1) * tabWidth
+			for ; pos < next; pos++ {
+				runes = append(runes, ' ')
+				positions = append(positions, pos)
+			}
+			continue
+		}
+		runes = append(runes, r)
+		positions = append(positions, pos)
+		pos += runewidth.RuneWidth(r)
+	}
+	return runes, positions
+}
+
+// blankMap returns for each display position of the line, if it is blank.
+// Positions beyond the end of the line are treated as blank by the caller.
+func blankMap(line string) []bool {
+	runes, positions := displayCells(line)
+	width := 0
+	if len(runes) > 0 {
+		width = positions[len(positions)-1] + runewidth.RuneWidth(runes[len(runes)-1])
+	}
+	blanks := make([]bool, width)
+	for i := range blanks {
+		blanks[i] = true
+	}
+	for i, r := range runes {
+		if r == ' ' {
+			continue
+		}
+		for p := positions[i]; p < positions[i]+max(runewidth.RuneWidth(r), 1) && p < width; p++ {
+			blanks[p] = false
+		}
+	}
+	return blanks
+}
+
+// BoundsFromWidths converts a list of column widths into column start positions.
+// Text beyond the sum of all widths forms an additional last column.
+func BoundsFromWidths(widths []int) T_bounds {
+	bounds := T_bounds{0}
+	pos := 0
+	for _, w := range widths {
+		pos += w
+		bounds = append(bounds, pos)
+	}
+	return bounds
+}
+
+// GetColumnBounds infers the column start positions of fixed-width data.
+// The candidates are the start offsets of the words in the headline (first line).
+// A candidate is only used, if there is a gutter - a position that is blank in all lines -
+// directly before it or, for right adjusted columns, somewhere between it and the previous column.
+// Candidates that would produce a column without any content in the data lines are merged into
+// the previous column, so headers like 'Mounted on' stay together.
+func GetColumnBounds(data T_rawdata) T_bounds {
+	if len(data) == 0 {
+		return T_bounds{0}
+	}
+
+	// gutter[p] is true, if position p is blank in every line
+	var gutter []bool
+	for i, line := range data {
+		blanks := blankMap(line)
+		if i == 0 {
+			gutter = blanks
+			continue
+		}
+		for p := range gutter {
+			if p < len(blanks) && !blanks[p] {
+				gutter[p] = false
+			}
+		}
+	}
+
+	header := blankMap(data[0])
+	bounds := T_bounds{0}
+	for p := 1; p < len(header); p++ {
+		if header[p] || !header[p-1] {
+			continue // not the start of a word
+		}
+		prev := bounds[len(bounds)-1]
+		for g := p - 1; g > prev; g-- {
+			if gutter[g] {
+				bounds = append(bounds, g+1)
+				break
+			}
+		}
+	}
+
+	return dropEmptyColumns(data, bounds)
+}
+
+// dropEmptyColumns removes column bounds, where the column has no content in any data line.
+func dropEmptyColumns(data T_rawdata, bounds T_bounds) T_bounds {
+	if len(data) < 2 {
+		return bounds
+	}
+	used := make([]bool, len(bounds))
+	used[0] = true
+	for _, line := range data[1:] {
+		for col, val := range FixedLineParse(line, bounds) {
+			if val != "" {
+				used[col] = true
+			}
+		}
+	}
+	nb := T_bounds{}
+	for col, b := range bounds {
+		if used[col] {
+			nb = append(nb, b)
+		}
+	}
+	return nb
+}
+
+// FixedLineParse slices a text line at the display positions given by bounds.
+// A wide rune belongs to the column in which it starts. The fields are trimmed,
+// empty fields are kept, so every line has the same number of columns.
+func FixedLineParse(line string, bounds T_bounds) T_dataline {
+	fields := make([]strings.Builder, len(bounds))
+	runes, positions := displayCells(line)
+	col := 0
+	for i, r := range runes {
+		for col+1 < len(bounds) && positions[i] >= bounds[col+1] {
+			col++
+		}
+		fields[col].WriteRune(r)
+	}
+	dataline := make(T_dataline, len(bounds))
+	for i := range fields {
+		dataline[i] = strings.TrimSpace(fields[i].String())
+	}
+	return dataline
+}
@@ -0,0 +1,247 @@
+package pc
+
+import (
+	"fmt"
+	"os"
+	ap "pc/argparse"
+	ld "pc/loaddata"
+	"strings"
+)
+
+// t_follower prints rows as they arrive with column widths growing over time
+type t_follower struct {
+	sep       rune
+	name      string // input name for the positions of -ragged
+	line      int    // number of input lines accepted so far
+	measuring bool   // first pass of Stream, ragged rows are reported in the second pass
+	parse     func(string) T_dataline
+	header    T_dataline
+	maxlen    T_maxlenghts
+	rows      int // printed data rows since the last header
+	fields    int // number of fields of the headline, for -ragged
+	filters   *t_filters
+	where     *T_where     // parsed -where, when the headline is known
+	lead      int          // remaining -lead rows, that are not filtered
+	matches   T_matches    // rows selected by -where and -filter, for -count
+	before    T_parsedData // the last -B rows, that did not match
+	after     int          // remaining -A rows after the last match
+}
+
+// match returns true, if the line or its parsed fields match the -filter pattern and
+// the -where expression
+func (f *t_follower) match(line string, row T_dataline) bool {
+	if f.where != nil && !f.where.Match(row) {
+		return false
+	}
+	return f.filters == nil || f.filters.match(line, row)
+}
+
+// grow widens the column widths for the row and returns true, if a width changed
+func (f *t_follower) grow(row T_dataline) bool {
+	changed := false
+	for col, val := range row {
+		for _, l := range strings.Split(val, "\n") {
+			if w := displayWidth(l); col >= len(f.maxlen) || w > f.maxlen[col] {
+				f.maxlen.setMl(col, w)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// print formats the row to the current column widths and prints it
+func (f *t_follower) print(row T_dataline) {
+	line := append(T_dataline{}, row...)
+	if !ap.CmdParams.Nf {
+		line.GenerateLine(NewColumns(nil, f.maxlen))
+	}
+	data := T_parsedData{line}
+	data.PrintAsciiTab(f.maxlen)
+}
+
+// trenner returns a separator line of the current column widths
+func (f *t_follower) trenner(c string) T_dataline {
+	trenner := make(T_dataline, len(f.maxlen))
+	for i, v := range f.maxlen {
+		trenner[i] = strings.Repeat(c, v)
+	}
+	return trenner
+}
+
+// printHeader prints the header with the separator lines of -ts or -pp like Format does
+func (f *t_follower) printHeader() {
+	if f.header == nil {
+		return
+	}
+	if ap.CmdParams.Pp {
+		f.print(f.trenner("-"))
+	}
+	f.print(f.header)
+	if ap.CmdParams.Pp {
+		f.print(f.trenner("-"))
+	} else if ap.CmdParams.Ts {
+		f.print(f.trenner("="))
+	}
+	f.rows = 0
+}
+
+// accept parses the line, applies the filter and selects the columns.
+// It returns the rows to print: none, if the line does not match the filter, or the kept
+// -B context rows followed by the row. The header and the -lead rows are never filtered.
+func (f *t_follower) accept(line string, isHeader bool) T_parsedData {
+	if f.parse == nil {
+		// the first line defines the column bounds of fixed-width input
+		f.parse = getLineParser(&ap.CmdParams, T_rawdata{line}, f.sep)
+	}
+	row := f.parse(line)
+	num := f.line + 1
+	f.line += 1 + strings.Count(line, "\n")
+	if f.fields == 0 {
+		f.fields = len(row)
+	} else if len(row) != f.fields {
+		title := "the headline"
+		if ap.CmdParams.Nhl && ap.CmdParams.Header == "" {
+			title = "the first row"
+		}
+		msg := fmt.Errorf("row has %d fields, %s has %d", len(row), title, f.fields)
+		switch ap.CmdParams.Ragged {
+		case RaggedError:
+			ap.Fail(ap.ErrorAt(ap.ErrData, msg, f.name, num, min(len(row), f.fields)+1))
+		case RaggedReport:
+			if !f.measuring {
+				fmt.Fprintln(os.Stderr, ap.ErrorAt(ap.ErrData, msg, f.name, num, 0))
+			}
+		default:
+			row = fitRow(row, f.fields, ap.CmdParams.Ragged, line, raggedJoiner(f.sep))
+		}
+	}
+	if ap.CmdParams.Where != "" && f.where == nil {
+		// the titles of the headline or of -header name the columns
+		header := T_dataline{}
+		if isHeader {
+			header = row
+		}
+		if ap.CmdParams.Header != "" {
+			header = LineParse(ap.CmdParams.Header, f.sep)
+		}
+		var err error
+		if f.where, err = ParseWhere(ap.CmdParams.Where, header); err != nil {
+			ap.Fail(err)
+		}
+	}
+	filtered := !isHeader && f.lead == 0
+	matched := filtered && f.match(line, row)
+	if len(ap.CmdParams.ColSpecs) > 0 {
+		// the titles of the headline name the columns, with -nhl the first row gives the width
+		var header T_dataline
+		if isHeader {
+			header = row
+		}
+		if err := resolveColumns(&ap.CmdParams, header, len(row)); err != nil {
+			ap.Fail(err)
+		}
+	}
+	if len(ap.CmdParams.Columns) > 0 {
+		row.selectColumns(ap.CmdParams.Columns)
+	}
+	if isHeader {
+		if err := ap.RenameColumns(row, ap.CmdParams.Rename); err != nil {
+			ap.Fail(err)
+		}
+	}
+	if !filtered {
+		if !isHeader {
+			f.lead--
+		}
+		return T_parsedData{row}
+	}
+	f.matches.Total++
+	if matched {
+		f.matches.Matched++
+		rows := append(f.before, row)
+		f.before, f.after = nil, ap.CmdParams.After
+		return rows
+	}
+	if f.after > 0 {
+		f.after--
+		return T_parsedData{row}
+	}
+	if ap.CmdParams.Before > 0 {
+		f.before = append(f.before, row)
+		if len(f.before) > ap.CmdParams.Before {
+			f.before = f.before[1:]
+		}
+	}
+	return nil
+}
+
+// add processes one input line: filter, parse, select the columns and print it
+func (f *t_follower) add(line string) {
+	isHeader := f.header == nil && !ap.CmdParams.Nhl
+	for _, row := range f.accept(line, isHeader) {
+		changed := f.grow(row)
+		if isHeader {
+			f.header = row
+			f.printHeader()
+			return
+		}
+		if f.header != nil && ((changed && ap.CmdParams.HeadWidth) ||
+			(ap.CmdParams.HeadEvery > 0 && f.rows >= ap.CmdParams.HeadEvery)) {
+			f.printHeader()
+		}
+		f.print(row)
+		f.rows++
+	}
+}
+
+// newFollower returns a follower for lines separated by sep with the -filter of the parameters
+func newFollower(sep rune) *t_follower {
+	f := &t_follower{sep: sep, name: ld.StdinName, lead: ap.CmdParams.Lead}
+	if len(ap.CmdParams.Files) > 0 {
+		f.name = ap.CmdParams.Files[0]
+	}
+	if ap.CmdParams.Header != "" {
+		header := LineParse(ap.CmdParams.Header, sep)
+		f.fields = len(header)
+		if err := resolveColumns(&ap.CmdParams, header, len(header)); err != nil {
+			ap.Fail(err)
+		}
+	}
+	var err error
+	if f.filters, err = setFilters(&ap.CmdParams); err != nil {
+		ap.Fail(err)
+	}
+	return f
+}
+
+// start prints the header defined by -header, if any
+func (f *t_follower) start() {
+	if ap.CmdParams.Header != "" {
+		var err error
+		if f.header, err = headerLine(&ap.CmdParams, f.sep); err != nil {
+			ap.Fail(err)
+		}
+		f.grow(f.header)
+		f.printHeader()
+	}
+}
+
+// FollowFormat reads lines from the channel until it is closed and prints each line, as soon
+// as it arrives, aligned to the column widths seen so far. The widths only grow.
+// With -hwidth the header is printed again when a width changes, with -hevery=N every N rows.
+func FollowFormat(lines <-chan string, sep rune) {
+	f := newFollower(sep)
+	f.start()
+	for line := range lines {
+		f.add(line)
+	}
+	f.footer()
+}
+
+// footer prints the footer of -count
+func (f *t_follower) footer() {
+	if ap.CmdParams.Count {
+		fmt.Println(f.matches)
+	}
+}
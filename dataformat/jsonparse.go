@@ -0,0 +1,194 @@
+package pc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	ap "pc/argparse"
+	"strconv"
+	"strings"
+)
+
+// Array handling of JSON input, selected by -jarr
+const (
+	JsonArrayJoin  = "join"  // scalar elements joined by ',' in one cell
+	JsonArrayIndex = "index" // one column per element, named key.0, key.1, ...
+	JsonArrayJSON  = "json"  // the array as compact JSON text in one cell
+)
+
+// joinKey builds the dotted path of a nested key
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// jsonScalar returns the text of a JSON string, number, bool or null value
+func jsonScalar(raw json.RawMessage) string {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return string(raw)
+	}
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	return string(raw)
+}
+
+// compactJSON returns the JSON text without insignificant blanks
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// flattenJSON adds the value to the record. Nested objects get dotted keys like metadata.name,
+// arrays are handled as defined by jarr (-jarr).
+func flattenJSON(raw json.RawMessage, prefix string, rec *t_record, jarr string) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	switch raw[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.Token() // opening brace
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return ap.NewError(ap.ErrData, "error reading json object: %w", err)
+			}
+			var child json.RawMessage
+			if err := dec.Decode(&child); err != nil {
+				return ap.NewError(ap.ErrData, "error reading json object: %w", err)
+			}
+			if err := flattenJSON(child, joinKey(prefix, tok.(string)), rec, jarr); err != nil {
+				return err
+			}
+		}
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return ap.NewError(ap.ErrData, "error reading json array: %w", err)
+		}
+		switch jarr {
+		case JsonArrayIndex:
+			for i, e := range elements {
+				if err := flattenJSON(e, joinKey(prefix, strconv.Itoa(i)), rec, jarr); err != nil {
+					return err
+				}
+			}
+		case JsonArrayJSON:
+			rec.set(prefix, compactJSON(raw))
+		default:
+			vals := []string{}
+			for _, e := range elements {
+				if e = bytes.TrimSpace(e); e[0] == '{' || e[0] == '[' {
+					vals = append(vals, compactJSON(e))
+				} else {
+					vals = append(vals, jsonScalar(e))
+				}
+			}
+			rec.set(prefix, strings.Join(vals, ","))
+		}
+	default:
+		if prefix == "" {
+			prefix = "value"
+		}
+		rec.set(prefix, jsonScalar(raw))
+	}
+	return nil
+}
+
+// kubernetesItems returns the elements of the "items" array, if the value is a single
+// list object as printed by 'kubectl get -o json'.
+func kubernetesItems(raw json.RawMessage) ([]json.RawMessage, bool) {
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil || list.Items == nil {
+		return nil, false
+	}
+	return list.Items, true
+}
+
+// JsonParse parses the raw lines as a JSON array of objects or as newline delimited JSON objects.
+// A single object with an "items" array, like the output of 'kubectl get -o json', is treated as
+// the array of its items.
+// Every object becomes one row. The header in the first row is the union of all keys in
+// order of their first appearance, keys missing in an object get an empty value.
+func JsonParse(data T_rawdata) T_parsedData {
+	pdata, err := jsonParse(data, ap.CmdParams.Jarr)
+	if err != nil {
+		ap.Fail(err)
+	}
+	return pdata
+}
+
+// textPosition returns the line and column of the byte offset in the text
+func textPosition(text string, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(text)))
+	before := text[:offset]
+	return strings.Count(before, "\n") + 1, len(before) - strings.LastIndex(before, "\n")
+}
+
+// jsonError returns the error of malformed JSON with its line and column in the text
+func jsonError(text string, err error) error {
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		line, col := textPosition(text, se.Offset)
+		return ap.ErrorAt(ap.ErrData, fmt.Errorf("error reading json input: %w", err), "", line, col)
+	case errors.As(err, &te):
+		line, col := textPosition(text, te.Offset)
+		return ap.ErrorAt(ap.ErrData, fmt.Errorf("error reading json input: %w", err), "", line, col)
+	}
+	return ap.ErrorAt(ap.ErrData, fmt.Errorf("error reading json input: %w", err), "", 0, 0)
+}
+
+// jsonParse parses the raw lines as JSON with arrays handled as defined by jarr.
+// Malformed input is returned as error with its line and column.
+func jsonParse(data T_rawdata, jarr string) (T_parsedData, error) {
+	text := strings.TrimSpace(strings.Join(data, "\n"))
+	dec := json.NewDecoder(strings.NewReader(text))
+	raws := []json.RawMessage{}
+	if strings.HasPrefix(text, "[") {
+		dec.Token() // opening bracket of the array
+	}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, jsonError(text, err)
+		}
+		raws = append(raws, raw)
+	}
+	if len(raws) == 1 && !strings.HasPrefix(text, "[") {
+		if items, ok := kubernetesItems(raws[0]); ok {
+			raws = items
+		}
+	}
+
+	records := []t_record{}
+	for _, raw := range raws {
+		rec := newRecord()
+		if err := flattenJSON(raw, "", &rec, jarr); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return recordsToData(records), nil
+}
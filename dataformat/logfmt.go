@@ -0,0 +1,92 @@
+package pc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// unquoteValue removes the quotes of a double or single quoted value
+func unquoteValue(val string) string {
+	if len(val) < 2 {
+		return val
+	}
+	if val[0] == '"' && val[len(val)-1] == '"' {
+		if s, err := strconv.Unquote(val); err == nil {
+			return s
+		}
+		return val[1 : len(val)-1]
+	}
+	if val[0] == '\'' && val[len(val)-1] == '\'' {
+		return val[1 : len(val)-1]
+	}
+	return val
+}
+
+// scanLogfmt walks through the line like splitFields, but also knows backslash escaped quotes
+// inside of double quoted values. For every rune outside of quotes it calls split, if split
+// returns true, the text up to this rune is a finished token.
+func scanLogfmt(s string, split func(r rune) bool) []string {
+	var tokens []string
+	start := 0
+	inQuoteSingle, inQuoteDouble, escaped := false, false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuoteDouble:
+			escaped = true
+		case r == '\'' && !inQuoteDouble:
+			inQuoteSingle = !inQuoteSingle
+		case r == '"' && !inQuoteSingle:
+			inQuoteDouble = !inQuoteDouble
+		case !inQuoteSingle && !inQuoteDouble && split(r):
+			tokens = append(tokens, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(tokens, s[start:])
+}
+
+// splitKeyValue splits a field at the first '=' outside of quotes.
+// A bare key without '=' is a boolean flag with the value "true".
+func splitKeyValue(field string) (string, string) {
+	found := false
+	kv := scanLogfmt(field, func(r rune) bool {
+		if r == '=' && !found {
+			found = true
+			return true
+		}
+		return false
+	})
+	if len(kv) < 2 {
+		return unquoteValue(field), "true"
+	}
+	return unquoteValue(kv[0]), unquoteValue(kv[1])
+}
+
+// logfmtLineParse parses a logfmt line like 'level=info msg="started" dur=12ms' into a record.
+func logfmtLineParse(line string) t_record {
+	rec := newRecord()
+	for _, field := range scanLogfmt(line, func(r rune) bool { return r == ' ' || r == '\t' }) {
+		if field == "" {
+			continue
+		}
+		key, val := splitKeyValue(field)
+		rec.set(key, val)
+	}
+	return rec
+}
+
+// LogfmtParse parses the raw lines as logfmt key=value pairs, quoted values may contain blanks.
+// Every line becomes one row. The header in the first row is the union of all keys in
+// order of their first appearance, keys missing in a line get an empty value.
+func LogfmtParse(data T_rawdata) T_parsedData {
+	records := []t_record{}
+	for _, l := range data {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		records = append(records, logfmtLineParse(l))
+	}
+	return recordsToData(records)
+}
@@ -2,8 +2,6 @@ package pc
 
 import (
 	"strings"
-
-	"github.com/mattn/go-runewidth"
 )
 
 type T_maxlenghts []int
@@ -26,10 +24,14 @@ func GetMaxLength(d T_parsedData) T_maxlenghts {
 	maxlengths := T_maxlenghts{}
 	for _, line := range d {
 		for col, val := range line {
-			lines := strings.Split(val, "\n")
-			for _, l := range lines {
-				length := runewidth.StringWidth(l)
-				maxlengths.setMl(col, length)
+			// the widest of the lines of a multiline cell
+			for {
+				l, rest, more := strings.Cut(val, "\n")
+				maxlengths.setMl(col, displayWidth(l))
+				if !more {
+					break
+				}
+				val = rest
 			}
 		}
 	}
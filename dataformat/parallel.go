@@ -0,0 +1,36 @@
+package pc
+
+import (
+	"sync"
+)
+
+// minParallelLines is the number of lines, below which parsing is not split among workers
+const minParallelLines = 10000
+
+// parallelChunks splits the indices 0..n-1 into consecutive chunks and calls fn for every chunk.
+// With more than 1 worker (-j) and enough lines the chunks are processed concurrently.
+// fn must only write results to the positions of its chunk, so the order of the input is kept.
+func parallelChunks(workers, n int, fn func(start, end int)) {
+	if workers <= 1 || n < minParallelLines {
+		fn(0, n)
+		return
+	}
+	// more chunks than workers, so a slow chunk does not keep the others waiting
+	size := max(n/(workers*4), 1000)
+	chunks := make(chan [2]int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				fn(c[0], c[1])
+			}
+		}()
+	}
+	for start := 0; start < n; start += size {
+		chunks <- [2]int{start, min(start+size, n)}
+	}
+	close(chunks)
+	wg.Wait()
+}
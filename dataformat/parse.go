@@ -2,20 +2,25 @@ package pc
 
 import (
 	"fmt"
+	"os"
 	ap "pc/argparse"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
 // LineParse splits a text line into fields, optionally handling columns with multiple spaces.
 // It returns a slice of strings (T_dataline).
 func LineParse(line string, sep rune) T_dataline {
+	return lineParse(line, sep, ap.CmdParams.MoreBlanks)
+}
+
+// lineParse splits a text line into fields, with moreBlanks two or more blanks separate the fields.
+func lineParse(line string, sep rune, moreBlanks bool) T_dataline {
 
 	var fields []string
 
 	// Handle the case of multiple spaces as separators
-	if sep == ' ' && ap.CmdParams.MoreBlanks {
+	if sep == ' ' && moreBlanks {
 		line = handleMultipleSpaces(line)
 		fields = splitFields(line, '\n')
 	} else {
@@ -25,11 +30,13 @@ func LineParse(line string, sep rune) T_dataline {
 	return T_dataline(fields)
 }
 
+// multipleSpaces matches the field separator of -mb, two or more blanks
+var multipleSpaces = regexp.MustCompile(`\s{2,}`)
+
 // handleMultipleSpaces replaces multiple spaces with a single space and ensures single-character fields are recognized.
 func handleMultipleSpaces(s string) string {
 	// Replace multiple spaces with a single space
-	re1 := regexp.MustCompile(`\s{2,}`)
-	s = re1.ReplaceAllString(s, "\n")
+	s = multipleSpaces.ReplaceAllString(s, "\n")
 
 	return s
 }
@@ -74,48 +81,204 @@ func GetLineSlice(line string) string {
 	return fmt.Sprintln(LineParse(line, ' '))
 }
 
-// setFilter returns the col for filter (-1 if col undefined) and the compiled regexp
-func setFilter() (int, *regexp.Regexp) {
-	col := -1
-	filterString := ap.CmdParams.Filter
-	if ap.CmdParams.Filter != "" {
-		filtertRegExp := regexp.MustCompile(`^(\d+)=(.*)$`)
-		if filtertRegExp.MatchString(ap.CmdParams.Filter) {
-			res := filtertRegExp.FindAllStringSubmatch(ap.CmdParams.Filter, -1)
-			i, err := strconv.Atoi(res[0][1])
-			if err == nil {
-				col = i - 1
-				filterString = res[0][2]
-				fmt.Println("col:", col, "pattern", filterString)
+// t_filter is a compiled -filter pattern
+type t_filter struct {
+	col int // index of the searched column, -1 for the whole row
+	re  *regexp.Regexp
+}
+
+// t_filters are the compiled -filter patterns, that must all match, and -fv
+type t_filters struct {
+	list   []t_filter
+	invert bool
+}
+
+// setFilters compiles the -filter patterns, with -fi case-insensitive.
+// Without -filter it returns nil.
+func setFilters(p *ap.T_flags) (*t_filters, error) {
+	if len(p.Filter) == 0 {
+		return nil, nil
+	}
+	f := &t_filters{invert: p.FilterInv}
+	for _, filter := range p.Filter {
+		col, filterString := ap.FilterParts(filter)
+		if col > -1 && p.Verify {
+			fmt.Fprintln(os.Stderr, "col:", col, "pattern", filterString)
+		}
+		if p.FilterCase {
+			filterString = "(?i)" + filterString
+		}
+		dataRegExp, err := regexp.Compile(filterString)
+		if err != nil {
+			return nil, ap.NewError(ap.ErrParam, "-filter='%s' must be a valid regex. %v", filter, err)
+		}
+		f.list = append(f.list, t_filter{col: col, re: dataRegExp})
+	}
+	return f, nil
+}
+
+// match returns true, if all patterns match the text of the row or their column of the row,
+// with -fv if not all of them match
+func (f *t_filters) match(text string, row T_dataline) bool {
+	for _, flt := range f.list {
+		if flt.col < 0 && !flt.re.MatchString(text) ||
+			flt.col > -1 && (flt.col >= len(row) || !flt.re.MatchString(row[flt.col])) {
+			return f.invert
+		}
+	}
+	return !f.invert
+}
+
+// getLineParser returns the function used by DataParse to split a single input line.
+// For fixed-width input the column bounds are taken from -widths or inferred from the data.
+func getLineParser(p *ap.T_flags, data T_rawdata, sep rune) func(string) T_dataline {
+	if p.Fixed {
+		bounds := BoundsFromWidths(p.Widths)
+		if len(p.Widths) == 0 {
+			bounds = GetColumnBounds(data)
+		}
+		return func(l string) T_dataline { return FixedLineParse(l, bounds) }
+	}
+	moreBlanks := p.MoreBlanks
+	return func(l string) T_dataline { return lineParse(l, sep, moreBlanks) }
+}
+
+// filterRows applies the -filter patterns to parsed rows, with -j in concurrent chunks.
+// Without a filter column a pattern is matched against the text of the row, the input line
+// for line based input or the fields joined by the separator otherwise.
+// The first rows, the headline and the -lead rows, are kept unfiltered, the -A and -B rows
+// around a match are kept as context.
+// It returns the remaining rows together with their line or record numbers and the number
+// of matching rows.
+func filterRows(p *ap.T_flags, rows T_parsedData, nums []int, texts []string, first int) (T_parsedData, []int, int, error) {
+	filters, err := setFilters(p)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if filters == nil {
+		return rows, nums, len(rows) - first, nil
+	}
+	match := make([]bool, len(rows))
+	parallelChunks(p.Jobs, len(rows)-first, func(start, end int) {
+		for i := start + first; i < end+first; i++ {
+			match[i] = filters.match(texts[i], rows[i])
+		}
+	})
+	keep := make([]bool, len(rows))
+	for i := range first {
+		keep[i] = true
+	}
+	matched := 0
+	for i := first; i < len(rows); i++ {
+		if match[i] {
+			matched++
+			for j := max(first, i-p.Before); j <= min(len(rows)-1, i+p.After); j++ {
+				keep[j] = true
 			}
 		}
-	} else {
-		filterString = `.`
 	}
-	dataRegExp := regexp.MustCompile(filterString)
-	return col, dataRegExp
+	nd := T_parsedData{}
+	nnums := []int{}
+	for i, row := range rows {
+		if keep[i] {
+			nd = append(nd, row)
+			nnums = append(nnums, nums[i])
+		}
+	}
+	return nd, nnums, matched, nil
 }
 
 // DataParse parses an slice of stringlines into T_parsedData ( [][]string )
 func DataParse(data T_rawdata, sep rune) T_parsedData {
-	pdata := T_parsedData{}
-	var filterRegExp *regexp.Regexp
-	filterCol := -1
-	filter := ap.CmdParams.Filter != ""
-	if filter {
-		filterCol, filterRegExp = setFilter()
-	}
-	for _, l := range data {
-		var dataline T_dataline
-		if !filter || (filterCol < 0 && filterRegExp.MatchString(l)) {
-			dataline = LineParse(l, sep)
-			pdata = append(pdata, dataline)
-		} else {
-			dataline = LineParse(l, sep)
-			if filterCol > -1 && filterCol < len(dataline) && filterRegExp.MatchString(dataline[filterCol]) {
-				pdata = append(pdata, dataline)
-			}
-		}
+	pdata, _, _, err := dataParse(&ap.CmdParams, data, sep, "")
+	if err != nil {
+		ap.Fail(err)
 	}
 	return pdata
 }
+
+// ParseLines parses and filters the lines as defined by the parameters p.
+// Unlike DataParse it does not use the command line parameters and returns errors.
+func ParseLines(data T_rawdata, p *ap.T_flags) (T_parsedData, error) {
+	pdata, _, _, err := dataParse(p, data, []rune(p.Sep)[0], "")
+	return pdata, err
+}
+
+// ParseLinesMatches is ParseLines, that also counts the rows selected by -where and -filter.
+func ParseLinesMatches(data T_rawdata, p *ap.T_flags) (T_parsedData, T_matches, error) {
+	pdata, _, m, err := dataParse(p, data, []rune(p.Sep)[0], "")
+	return pdata, m, err
+}
+
+// dataParse parses and filters the data of the input name and returns the rows together with
+// their origin: the input line number for line based input, the record number otherwise.
+// In both cases the first line or record, the headline, has the number 1.
+// Rows with more or less fields than the headline are handled as defined by -ragged,
+// then the rows are selected by -where and -filter, see selectRows.
+func dataParse(p *ap.T_flags, data T_rawdata, sep rune, name string) (T_parsedData, []int, T_matches, error) {
+	rows, nums, texts, err := parseInput(p, data, sep)
+	if err != nil {
+		return nil, nil, T_matches{}, err
+	}
+	if err := raggedRows(p, rows, nums, texts, name, sep); err != nil {
+		return nil, nil, T_matches{}, err
+	}
+	return selectRows(p, rows, nums, texts, sep)
+}
+
+// recordTexts numbers the records and joins their fields to the texts for the filter
+func recordTexts(rows T_parsedData, sep rune) (T_parsedData, []int, []string, error) {
+	nums := make([]int, len(rows))
+	texts := make([]string, len(rows))
+	for i, row := range rows {
+		nums[i] = i + 1
+		texts[i] = strings.Join(row, string(sep))
+	}
+	return rows, nums, texts, nil
+}
+
+// parseInput parses the data with the selected input mode without filtering.
+// Line based input is parsed by -j concurrent workers, the order of the lines is kept.
+// Together with the rows it returns their line or record numbers and the texts,
+// that are matched by the filter.
+func parseInput(p *ap.T_flags, data T_rawdata, sep rune) (T_parsedData, []int, []string, error) {
+	switch {
+	case p.Icsv || p.Itsv:
+		rows, err := csvParse(data, sep)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return recordTexts(rows, sep)
+	case p.Ijson:
+		rows, err := jsonParse(data, p.Jarr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return recordTexts(rows, sep)
+	case p.Istanza:
+		return recordTexts(StanzaParse(data), sep)
+	case p.Ilogfmt:
+		return recordTexts(LogfmtParse(data), sep)
+	case p.Pattern != "":
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid -pattern %q: %w", p.Pattern, err)
+		}
+		return recordTexts(patternParse(data, re, p.NoMatch), sep)
+	}
+	pdata := make(T_parsedData, len(data))
+	nums := make([]int, len(data))
+	parse := getLineParser(p, data, sep)
+	parallelChunks(p.Jobs, len(data), func(start, end int) {
+		for i := start; i < end; i++ {
+			pdata[i] = parse(data[i])
+		}
+	})
+	lineNo := 1
+	for i, l := range data {
+		nums[i] = lineNo
+		// lines merged by MergeQuotedLines count with all their original lines
+		lineNo += 1 + strings.Count(l, "\n")
+	}
+	return pdata, nums, data, nil
+}
@@ -0,0 +1,68 @@
+package pc
+
+import (
+	ap "pc/argparse"
+	"regexp"
+	"strconv"
+)
+
+// Handling of lines, that do not match the -pattern regex, selected by -nomatch
+const (
+	NoMatchDrop   = "drop"   // ignore the line
+	NoMatchKeep   = "keep"   // keep the line in an additional last column
+	NoMatchAppend = "append" // append the line to the last cell of the previous row, e.g. for stack traces
+)
+
+// unmatchedHeader is the title of the catch-all column for -nomatch=keep
+const unmatchedHeader = "UNMATCHED"
+
+// patternHeader returns the names of the capture groups, unnamed groups get their number as name
+func patternHeader(re *regexp.Regexp) T_dataline {
+	header := T_dataline{}
+	for i, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		header = append(header, name)
+	}
+	return header
+}
+
+// PatternParse matches every line against the regex. The capture groups of a matching line
+// become the columns of a row, the group names form the header in the first row.
+// Lines that do not match are handled as defined by -nomatch.
+func PatternParse(data T_rawdata, re *regexp.Regexp) T_parsedData {
+	return patternParse(data, re, ap.CmdParams.NoMatch)
+}
+
+// patternParse parses the lines with the regex, not matching lines are handled as defined by nomatch
+func patternParse(data T_rawdata, re *regexp.Regexp, nomatch string) T_parsedData {
+	header := patternHeader(re)
+	if nomatch == NoMatchKeep {
+		header = append(header, unmatchedHeader)
+	}
+	pdata := T_parsedData{header}
+	for _, l := range data {
+		match := re.FindStringSubmatch(l)
+		if match != nil {
+			row := T_dataline(match[1:])
+			if nomatch == NoMatchKeep {
+				row = append(row, "")
+			}
+			pdata = append(pdata, row)
+			continue
+		}
+		switch nomatch {
+		case NoMatchKeep:
+			row := make(T_dataline, len(header))
+			row[len(row)-1] = l
+			pdata = append(pdata, row)
+		case NoMatchAppend:
+			if len(pdata) > 1 {
+				last := pdata[len(pdata)-1]
+				last[len(last)-1] += "\n" + l
+			}
+		}
+	}
+	return pdata
+}
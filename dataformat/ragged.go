@@ -0,0 +1,93 @@
+package pc
+
+import (
+	"fmt"
+	"os"
+	ap "pc/argparse"
+	"strings"
+)
+
+// Policies of -ragged for rows with more or less fields than the headline
+const (
+	RaggedKeep      = ""           // rows are kept as they are
+	RaggedPad       = "pad"        // missing fields are added empty
+	RaggedTruncate  = "truncate"   // missing fields are added empty, surplus fields are dropped
+	RaggedMergeLast = "merge-last" // missing fields are added empty, surplus fields are joined into the last column
+	RaggedError     = "error"      // the first ragged row is an error
+	RaggedReport    = "report"     // the ragged rows are reported on STDERR and kept
+)
+
+// raggedJoiner returns the text, that joins the surplus fields of -ragged=merge-last
+func raggedJoiner(sep rune) string {
+	if sep == ' ' {
+		return " "
+	}
+	return string(sep)
+}
+
+// fitRow returns the row fitted to width fields as defined by the -ragged policy.
+// text is the input line of the row, merge-last takes the surplus fields from it.
+func fitRow(row T_dataline, width int, policy string, text string, joiner string) T_dataline {
+	switch policy {
+	case RaggedPad, RaggedTruncate, RaggedMergeLast:
+		for len(row) < width {
+			row = append(row, "")
+		}
+	}
+	if width > 0 && len(row) > width {
+		switch policy {
+		case RaggedTruncate:
+			row = row[:width]
+		case RaggedMergeLast:
+			row = append(row[:width-1:width-1], mergeFields(row, width-1, text, joiner))
+		}
+	}
+	return row
+}
+
+// mergeFields returns the fields of the row from index k on as one field. It is the rest of the
+// input text from the start of field k, so the original separators and blanks are kept.
+// If the fields are not found in the text, they are joined by the joiner.
+func mergeFields(row T_dataline, k int, text string, joiner string) string {
+	pos := 0
+	for _, field := range row[:k+1] {
+		i := strings.Index(text[pos:], field)
+		if i < 0 {
+			return strings.Join(row[k:], joiner)
+		}
+		pos += i + len(field)
+	}
+	start := pos - len(row[k])
+	return strings.TrimRight(text[start:], " \t\r")
+}
+
+// raggedRows applies the -ragged policy to the rows with more or less fields than the
+// headline, the -header or, with -nhl, the first row. texts are the input lines of the rows.
+// Errors and reports name the input and the line or record number of the row.
+func raggedRows(p *ap.T_flags, rows T_parsedData, nums []int, texts []string, name string, sep rune) error {
+	if p.Ragged == RaggedKeep || len(rows) == 0 {
+		return nil
+	}
+	width, first, title := len(rows[0]), 1, "the headline"
+	if p.Header != "" {
+		width, first = len(lineParse(p.Header, sep, p.MoreBlanks)), 0
+	} else if p.Nhl {
+		title = "the first row"
+	}
+	for i := first; i < len(rows); i++ {
+		n := len(rows[i])
+		if n == width {
+			continue
+		}
+		msg := fmt.Errorf("row has %d fields, %s has %d", n, title, width)
+		switch p.Ragged {
+		case RaggedError:
+			return ap.ErrorAt(ap.ErrData, msg, name, nums[i], min(n, width)+1)
+		case RaggedReport:
+			fmt.Fprintln(os.Stderr, ap.ErrorAt(ap.ErrData, msg, name, nums[i], 0))
+		default:
+			rows[i] = fitRow(rows[i], width, p.Ragged, texts[i], raggedJoiner(sep))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package pc
+
+// t_record holds the key value pairs of one input record, e.g. a flattened JSON object,
+// with its keys in the order of appearance
+type t_record struct {
+	keys   []string
+	values map[string]string
+}
+
+// set stores a value for the key and remembers the order of the keys
+func (r *t_record) set(key, val string) {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = val
+}
+
+// newRecord returns an empty record
+func newRecord() t_record {
+	return t_record{values: map[string]string{}}
+}
+
+// recordsToData converts records into rows. The header in the first row is the union of
+// all keys in order of their first appearance, keys missing in a record get an empty value.
+func recordsToData(records []t_record) T_parsedData {
+	header := T_dataline{}
+	seen := map[string]bool{}
+	for _, rec := range records {
+		for _, k := range rec.keys {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+	}
+
+	pdata := T_parsedData{header}
+	for _, rec := range records {
+		row := make(T_dataline, len(header))
+		for i, k := range header {
+			row[i] = rec.values[k]
+		}
+		pdata = append(pdata, row)
+	}
+	return pdata
+}
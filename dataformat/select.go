@@ -0,0 +1,65 @@
+package pc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	ap "pc/argparse"
+)
+
+// T_matches counts the rows selected by -where and -filter for the footer of -count
+type T_matches struct {
+	Matched int // rows selected by -where and -filter
+	Total   int // rows checked by -where and -filter, without headline and -lead rows
+}
+
+// String returns the footer of -count
+func (m T_matches) String() string {
+	return fmt.Sprintf("%d of %d rows matched", m.Matched, m.Total)
+}
+
+// add adds the counts of another input
+func (m *T_matches) add(o T_matches) {
+	m.Matched += o.Matched
+	m.Total += o.Total
+}
+
+// headRows returns the number of leading rows, that are never filtered: the headline, unless
+// -nhl is set or -header defines it for an input without headline, and the -lead rows.
+func headRows(p *ap.T_flags, n int) int {
+	first := p.Lead
+	if !p.Nhl && p.Header == "" {
+		first++
+	}
+	return min(first, n)
+}
+
+// selectRows keeps the rows, that fulfill the -where expression and match the -filter pattern.
+// The headline and the -lead rows are always kept and not counted.
+func selectRows(p *ap.T_flags, rows T_parsedData, nums []int, texts []string, sep rune) (T_parsedData, []int, T_matches, error) {
+	first := headRows(p, len(rows))
+	m := T_matches{Total: len(rows) - first}
+	rows, nums, texts, err := whereRows(p, rows, nums, texts, sep, first)
+	if err != nil {
+		return nil, nil, T_matches{}, err
+	}
+	if rows, nums, m.Matched, err = filterRows(p, rows, nums, texts, first); err != nil {
+		return nil, nil, T_matches{}, err
+	}
+	return rows, nums, m, nil
+}
+
+// WriteMatches writes the footer of -count below the table to w, for CSV and JSON output
+// to STDERR, so the output stays valid.
+func WriteMatches(w io.Writer, p *ap.T_flags, m T_matches) error {
+	if !p.Count {
+		return nil
+	}
+	if p.Csv || p.Json {
+		w = os.Stderr
+	}
+	if _, err := fmt.Fprintln(w, m); err != nil {
+		return ap.ErrorAt(ap.ErrOutput, err, "", 0, 0)
+	}
+	return nil
+}
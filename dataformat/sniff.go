@@ -0,0 +1,198 @@
+package pc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	ap "pc/argparse"
+	"strings"
+)
+
+// SniffLines is the number of input lines, that DetectFormat looks at
+const SniffLines = 50
+
+// T_layout names the kind of input layout detected by DetectFormat
+type T_layout string
+
+const (
+	LayoutDelimited  T_layout = "delimited"   // fields separated by a character like , ; tab or |
+	LayoutBlanks     T_layout = "blanks"      // fields separated by one or more blanks
+	LayoutMoreBlanks T_layout = "more-blanks" // fields separated by two or more blanks
+	LayoutFixed      T_layout = "fixed-width" // whitespace aligned columns
+	LayoutJSON       T_layout = "json"        // a JSON document
+	LayoutNDJSON     T_layout = "ndjson"      // one JSON object per line
+)
+
+// T_format describes the detected layout of the input data.
+// Confidence is between 0 and 1, Columns is the most common number of columns in the sample.
+type T_format struct {
+	Layout     T_layout
+	Sep        rune
+	Columns    int
+	Confidence float64
+}
+
+// String returns a short human readable description of the format
+func (f T_format) String() string {
+	s := string(f.Layout)
+	if f.Layout == LayoutDelimited {
+		s += fmt.Sprintf(" by %q", f.Sep)
+	}
+	return fmt.Sprintf("%s, %d columns, confidence %.2f", s, f.Columns, f.Confidence)
+}
+
+// delimiterCandidates are the separators DetectFormat tries for delimited input
+var delimiterCandidates = []rune{',', ';', '\t', '|'}
+
+// columnConsistency returns the most common column count and the share of lines having it.
+func columnConsistency(counts []int) (int, float64) {
+	if len(counts) == 0 {
+		return 0, 0
+	}
+	freq := map[int]int{}
+	mode := 0
+	for _, c := range counts {
+		freq[c]++
+		if freq[c] > freq[mode] || (freq[c] == freq[mode] && c > mode) {
+			mode = c
+		}
+	}
+	return mode, float64(freq[mode]) / float64(len(counts))
+}
+
+// sniffJSON returns the JSON or NDJSON candidate of the input. A JSON document, that is valid
+// as a whole, and NDJSON with a valid object on every sampled line have the confidence 1.
+// Lines only starting with { or [, like '[INFO] started', give no or a lower confidence.
+func sniffJSON(data T_rawdata, sample []string) T_format {
+	first := strings.TrimSpace(sample[0])
+	if !strings.HasPrefix(first, "{") && !strings.HasPrefix(first, "[") {
+		return T_format{}
+	}
+	valid := 0
+	for _, l := range sample {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "{") && json.Valid([]byte(l)) {
+			valid++
+		}
+	}
+	if valid < len(sample) && json.Valid([]byte(strings.Join(data, "\n"))) {
+		return T_format{Layout: LayoutJSON, Columns: 1, Confidence: 1}
+	}
+	return T_format{Layout: LayoutNDJSON, Columns: 1, Confidence: float64(valid) / float64(len(sample))}
+}
+
+// sniffDelimited returns the best matching delimited format of the sample
+func sniffDelimited(sample []string) T_format {
+	best := T_format{Layout: LayoutDelimited}
+	for _, sep := range delimiterCandidates {
+		r := csv.NewReader(strings.NewReader(strings.Join(sample, "\n")))
+		r.Comma = sep
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		counts := []int{}
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				counts = append(counts, 0)
+				continue
+			}
+			counts = append(counts, len(record))
+		}
+		cols, conf := columnConsistency(counts)
+		if cols < 2 {
+			continue
+		}
+		if conf > best.Confidence || (conf == best.Confidence && cols > best.Columns) {
+			best = T_format{Layout: LayoutDelimited, Sep: sep, Columns: cols, Confidence: conf}
+		}
+	}
+	return best
+}
+
+// sniffBlanks returns the best matching blank separated or fixed-width format of the sample
+func sniffBlanks(sample []string) T_format {
+	single := []int{}
+	more := []int{}
+	for _, l := range sample {
+		single = append(single, len(splitFields(l, ' ')))
+		more = append(more, len(splitFields(handleMultipleSpaces(l), '\n')))
+	}
+	cols, conf := columnConsistency(single)
+	if conf == 1 {
+		return T_format{Layout: LayoutBlanks, Sep: ' ', Columns: cols, Confidence: 1}
+	}
+	mcols, mconf := columnConsistency(more)
+	if mconf == 1 && mcols > 1 {
+		return T_format{Layout: LayoutMoreBlanks, Sep: ' ', Columns: mcols, Confidence: 0.9}
+	}
+	if bounds := GetColumnBounds(sample); len(bounds) > 1 {
+		return T_format{Layout: LayoutFixed, Sep: ' ', Columns: len(bounds), Confidence: 0.8}
+	}
+	return T_format{Layout: LayoutBlanks, Sep: ' ', Columns: cols, Confidence: conf}
+}
+
+// DetectFormat samples the first n non empty lines of the input and returns the most likely layout:
+// JSON or NDJSON, delimited by one of , ; tab |, or blank separated, two-blank separated or fixed-width.
+func DetectFormat(data T_rawdata, n int) T_format {
+	sample := []string{}
+	for _, l := range data {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if sample = append(sample, l); len(sample) >= n {
+			break
+		}
+	}
+	if len(sample) == 0 {
+		return T_format{Layout: LayoutBlanks, Sep: ' '}
+	}
+	jsonFormat := sniffJSON(data, sample)
+	if jsonFormat.Confidence == 1 {
+		return jsonFormat
+	}
+	best := sniffBlanks(sample)
+	if delimited := sniffDelimited(sample); delimited.Columns > 1 && delimited.Confidence >= best.Confidence {
+		best = delimited
+	}
+	if jsonFormat.Confidence > best.Confidence {
+		return jsonFormat
+	}
+	return best
+}
+
+// inputFlags are the parameters, which explicitly select how the input is parsed
+var inputFlags = []string{"sep", "mb", "fixed", "widths", "icsv", "itsv", "ijson", "ilogfmt", "istanza", "pattern"}
+
+// ApplyFormat sets the input parameters according to a detected format.
+// Nothing is changed, if the input format was defined explicitly by parameters.
+// With -v the decision is reported on STDERR.
+func ApplyFormat(f T_format) {
+	for _, name := range inputFlags {
+		if ap.IsFlagSet(name) {
+			if ap.CmdParams.Verify {
+				fmt.Fprintf(os.Stderr, "Detected input format: %s, not used because -%s is set\n", f, name)
+			}
+			return
+		}
+	}
+	switch f.Layout {
+	case LayoutDelimited:
+		ap.CmdParams.Sep = string(f.Sep)
+		ap.CmdParams.Itsv = f.Sep == '\t'
+		ap.CmdParams.Icsv = f.Sep != '\t'
+	case LayoutMoreBlanks:
+		ap.CmdParams.MoreBlanks = true
+	case LayoutFixed:
+		ap.CmdParams.Fixed = true
+	case LayoutJSON, LayoutNDJSON:
+		ap.CmdParams.Ijson = true
+	}
+	if ap.CmdParams.Verify {
+		fmt.Fprintf(os.Stderr, "Detected input format: %s\n", f)
+	}
+}
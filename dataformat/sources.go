@@ -0,0 +1,172 @@
+package pc
+
+import (
+	ap "pc/argparse"
+	ld "pc/loaddata"
+	"strconv"
+)
+
+// Titles of the columns inserted by -fcol and -lcol
+const (
+	FileColHeader = "FILE"
+	LineColHeader = "LINE"
+)
+
+// sourceColumns prepends the FILE and LINE columns, as far as requested by -fcol and -lcol.
+// In the headline the titles of the columns are inserted instead of the values.
+func sourceColumns(row T_dataline, name string, num int, headline bool) T_dataline {
+	cols := T_dataline{}
+	if ap.CmdParams.FileCol {
+		if headline {
+			cols = append(cols, FileColHeader)
+		} else {
+			cols = append(cols, name)
+		}
+	}
+	if ap.CmdParams.LineCol {
+		if headline {
+			cols = append(cols, LineColHeader)
+		} else {
+			cols = append(cols, strconv.Itoa(num))
+		}
+	}
+	if len(cols) == 0 {
+		return row
+	}
+	return append(cols, row...)
+}
+
+// headerTitles returns the titles of the -header line, preceded by the titles of the
+// FILE and LINE columns, as far as requested by -fcol and -lcol.
+func headerTitles(p *ap.T_flags, sep rune) T_dataline {
+	titles := T_dataline{}
+	if p.FileCol {
+		titles = append(titles, FileColHeader)
+	}
+	if p.LineCol {
+		titles = append(titles, LineColHeader)
+	}
+	return append(titles, lineParse(p.Header, sep, p.MoreBlanks)...)
+}
+
+// SourcesParse parses the lines of every input source and concatenates the rows.
+// Unless -nhl or -header is set, only the headline of the first source is kept,
+// the headlines of the following sources are dropped instead of becoming data rows.
+// With -union the rows are aligned by the column titles instead of the column positions.
+func SourcesParse(sources []ld.T_source, sep rune) T_parsedData {
+	pdata, _ := SourcesParseMatches(sources, sep)
+	return pdata
+}
+
+// SourcesParseMatches is SourcesParse, that also counts the rows of all sources selected
+// by -where and -filter for the footer of -count.
+func SourcesParseMatches(sources []ld.T_source, sep rune) (T_parsedData, T_matches) {
+	if ap.CmdParams.Union {
+		return unionParse(sources, sep)
+	}
+	pdata, matches := T_parsedData{}, T_matches{}
+	for i, src := range sources {
+		rows, nums, m, err := dataParse(&ap.CmdParams, T_rawdata(src.Lines), sep, src.Name)
+		if err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrData, err, src.Name, 0, 0))
+		}
+		matches.add(m)
+		for j, row := range rows {
+			headline := nums[j] == 1 && !ap.CmdParams.Nhl && ap.CmdParams.Header == ""
+			if headline && i > 0 {
+				continue
+			}
+			pdata = append(pdata, sourceColumns(row, src.Name, nums[j], headline))
+		}
+	}
+	return pdata, matches
+}
+
+// t_unionHeader is the combined header of all inputs of -union
+type t_unionHeader struct {
+	names T_dataline
+	index map[string]int
+}
+
+// columnKey returns the key of a column title, repeated titles are counted, so they stay distinct
+func columnKey(name string, seen map[string]int) string {
+	seen[name]++
+	if seen[name] > 1 {
+		return name + "#" + strconv.Itoa(seen[name])
+	}
+	return name
+}
+
+// positions maps the columns of a source header to the columns of the combined header.
+// Titles not known yet are appended to the combined header.
+func (h *t_unionHeader) positions(header T_dataline) []int {
+	pos := make([]int, len(header))
+	seen := map[string]int{}
+	for i, name := range header {
+		key := columnKey(name, seen)
+		idx, ok := h.index[key]
+		if !ok {
+			idx = len(h.names)
+			h.index[key] = idx
+			h.names = append(h.names, name)
+		}
+		pos[i] = idx
+	}
+	return pos
+}
+
+// unionParse parses all sources and aligns their rows by the column titles of their headlines.
+// Columns missing in a source are filled with the -null marker, fields beyond the
+// headline of a source get their column number as title.
+func unionParse(sources []ld.T_source, sep rune) (T_parsedData, T_matches) {
+	header := t_unionHeader{index: map[string]int{}}
+	matches := T_matches{}
+	rows := T_parsedData{}
+	origins := []T_dataline{} // FILE and LINE columns of each row
+	for _, src := range sources {
+		parsed, nums, texts, err := parseInput(&ap.CmdParams, T_rawdata(src.Lines), sep)
+		if err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrData, err, src.Name, 0, 0))
+		}
+		if err := raggedRows(&ap.CmdParams, parsed, nums, texts, src.Name, sep); err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrData, err, src.Name, 0, 0))
+		}
+		parsed, nums, m, err := selectRows(&ap.CmdParams, parsed, nums, texts, sep)
+		if err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrData, err, src.Name, 0, 0))
+		}
+		matches.add(m)
+		if len(parsed) == 0 {
+			continue
+		}
+		srcHeader := parsed[0]
+		pos := header.positions(srcHeader)
+		data, dnums := parsed[1:], nums[1:]
+		for j, row := range data {
+			if len(row) > len(srcHeader) {
+				for len(srcHeader) < len(row) {
+					srcHeader = append(srcHeader, strconv.Itoa(len(srcHeader)+1))
+				}
+				pos = header.positions(srcHeader)
+			}
+			nrow := make(T_dataline, len(header.names))
+			for k := range nrow {
+				nrow[k] = ap.CmdParams.Null
+			}
+			for k, val := range row {
+				nrow[pos[k]] = val
+			}
+			rows = append(rows, nrow)
+			origins = append(origins, sourceColumns(T_dataline{}, src.Name, dnums[j], false))
+		}
+	}
+
+	pdata := T_parsedData{sourceColumns(header.names, "", 0, true)}
+	for i, row := range rows {
+		for len(row) < len(header.names) {
+			row = append(row, ap.CmdParams.Null)
+		}
+		pdata = append(pdata, append(origins[i], row...))
+	}
+	return pdata, matches
+}
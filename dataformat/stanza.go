@@ -0,0 +1,76 @@
+package pc
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stanzaKeyValue splits a line at the first ':' or '=' into key and value.
+// It returns false, if the line contains none of them.
+func stanzaKeyValue(line string) (string, string, bool) {
+	i := strings.IndexAny(line, ":=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), unquoteValue(strings.TrimSpace(line[i+1:])), true
+}
+
+// isPairLine returns true, if the line consists of several key="value" pairs with quoted values,
+// like the output of 'lsblk -P'
+func isPairLine(line string) bool {
+	fields := scanLogfmt(strings.TrimSpace(line), func(r rune) bool { return r == ' ' })
+	if len(fields) < 2 {
+		return false
+	}
+	for _, f := range fields {
+		if i := strings.Index(f, "="); i <= 0 || !strings.HasPrefix(f[i+1:], `"`) {
+			return false
+		}
+	}
+	return true
+}
+
+// StanzaParse turns blocks of 'Key: value' or 'Key=value' lines, separated by blank lines, into rows.
+// Indented lines and lines without separator continue the value of the previous key.
+// A line with several key=value pairs is a record of its own.
+// The header in the first row is the union of all keys in order of their first appearance.
+func StanzaParse(data T_rawdata) T_parsedData {
+	records := []t_record{}
+	rec := newRecord()
+	lastKey := ""
+	flush := func() {
+		if len(rec.keys) > 0 {
+			records = append(records, rec)
+		}
+		rec = newRecord()
+		lastKey = ""
+	}
+
+	for _, l := range data {
+		switch {
+		case strings.TrimSpace(l) == "":
+			flush()
+		case isPairLine(l):
+			flush()
+			rec = logfmtLineParse(l)
+			flush()
+		case lastKey != "" && unicode.IsSpace([]rune(l)[0]):
+			rec.values[lastKey] = strings.TrimPrefix(rec.values[lastKey]+"\n"+strings.TrimSpace(l), "\n")
+		default:
+			key, val, ok := stanzaKeyValue(l)
+			if !ok {
+				if lastKey != "" {
+					rec.values[lastKey] = strings.TrimPrefix(rec.values[lastKey]+"\n"+strings.TrimSpace(l), "\n")
+				}
+				continue
+			}
+			if old, exists := rec.values[key]; exists {
+				val = old + "\n" + val
+			}
+			rec.set(key, val)
+			lastKey = key
+		}
+	}
+	flush()
+	return recordsToData(records)
+}
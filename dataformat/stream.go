@@ -0,0 +1,190 @@
+package pc
+
+import (
+	"fmt"
+	"os"
+	ap "pc/argparse"
+	ld "pc/loaddata"
+)
+
+// streamBlockers returns the first parameter, that needs all rows in memory and so prevents
+// streaming, or "" if the input can be streamed.
+func streamBlockers() string {
+	p := ap.CmdParams
+	blockers := []struct {
+		name string
+		set  bool
+	}{
+		{"gcol", p.Gcol > 0},
+		{"csv", p.Csv},
+		{"json", p.Json},
+		{"num", p.Num},
+		{"rh", p.Rh},
+		{"fs", p.Fs},
+		{"auto", p.Auto},
+		{"icsv", p.Icsv},
+		{"itsv", p.Itsv},
+		{"ijson", p.Ijson},
+		{"ilogfmt", p.Ilogfmt},
+		{"istanza", p.Istanza},
+		{"pattern", p.Pattern != ""},
+		{"union", p.Union},
+		{"fcol", p.FileCol},
+		{"lcol", p.LineCol},
+		{"file", len(p.Files) > 1},
+	}
+	for _, b := range blockers {
+		if b.set {
+			return b.name
+		}
+	}
+	return ""
+}
+
+// largeSort returns true, if -sortcol sorts a single file larger than -sortmem.
+// The malformed rows of -strict are only checked for buffered data.
+func largeSort() bool {
+	p := ap.CmdParams
+	if p.SortCol == 0 || len(p.Files) != 1 || p.Strict {
+		return false
+	}
+	info, err := os.Stat(p.Files[0])
+	return err == nil && info.Size() > int64(p.SortMem)*1024*1024
+}
+
+// CanStream returns true, if -stream is set and no other parameter needs all rows in memory.
+// Otherwise the data is buffered as usual, with -v the reason is reported on STDERR.
+// A file larger than -sortmem, that is sorted by -sortcol, is streamed also without -stream,
+// so the sort can spill its rows to temporary files.
+func CanStream() bool {
+	auto := !ap.CmdParams.Stream && largeSort()
+	if !ap.CmdParams.Stream && !auto {
+		return false
+	}
+	if name := streamBlockers(); name != "" {
+		if ap.CmdParams.Verify && !auto {
+			fmt.Fprintf(os.Stderr, "-stream is not possible with -%s, the data is buffered\n", name)
+		}
+		return false
+	}
+	if ap.CmdParams.Verify && auto {
+		fmt.Fprintf(os.Stderr, "the file is larger than -sortmem, it is sorted in stream mode\n")
+	}
+	return true
+}
+
+// measure parses, filters and selects the lines and widens the column widths for them.
+// The first line is the header, unless -nhl is set.
+func (f *t_follower) measure(lines []string, first bool) {
+	for i, line := range lines {
+		isHeader := first && i == 0 && ap.CmdParams.Header == "" && !ap.CmdParams.Nhl
+		for _, row := range f.accept(line, isHeader) {
+			f.grow(row)
+		}
+	}
+}
+
+// sorted collects all rows of the lines, sorts them by -sortcol and prints them.
+// Rows beyond -sortmem are spilled to temporary files, so the input may be larger than memory.
+// The temporary files are removed, also after an error.
+func (f *t_follower) sorted(sample []string, lines <-chan string) error {
+	s := newExtSorter(int(ap.CmdParams.SortCol)-1, ap.CmdParams.SortMem*1024*1024)
+	defer s.close()
+	var top T_dataline // the first line, it is not sorted
+	first := true
+	collect := func(line string) error {
+		isHeader := first && !ap.CmdParams.Nhl
+		first = false
+		for _, row := range f.accept(line, isHeader) {
+			f.grow(row)
+			if isHeader {
+				top = row
+			} else if err := s.add(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, line := range sample {
+		if err := collect(line); err != nil {
+			return err
+		}
+	}
+	for line := range lines {
+		if err := collect(line); err != nil {
+			return err
+		}
+	}
+
+	f.start()
+	if f.header == nil {
+		f.header = top
+		f.printHeader()
+	} else if top != nil {
+		f.print(top)
+	}
+	if err := s.each(f.print); err != nil {
+		return err
+	}
+	if ap.CmdParams.Pp {
+		f.print(f.trenner("-"))
+	}
+	return nil
+}
+
+// Stream prints the file, or STDIN without filename, as ASCII table without holding all rows
+// in memory. The lines flow one by one through parse, filter, column selection and output.
+// The column bounds of fixed-width input are taken from the first -sample lines.
+// The column widths of a regular file are measured in a first pass over the file, for STDIN
+// they are taken from the first -sample lines and grow, when a later row is wider.
+// With -sortcol all rows are collected and sorted, see sorted.
+func Stream(fname string, sep rune) {
+	lines := make(chan string, 1024)
+	go ld.StreamFile(fname, lines)
+
+	sample := []string{}
+	for line := range lines {
+		if sample = append(sample, line); len(sample) >= ap.CmdParams.Sample {
+			break
+		}
+	}
+	f := newFollower(sep)
+	f.parse = getLineParser(&ap.CmdParams, T_rawdata(sample), sep)
+	if ap.CmdParams.SortCol > 0 {
+		if err := f.sorted(sample, lines); err != nil {
+			ap.Fail(err)
+		}
+		f.footer()
+		return
+	}
+
+	// the measuring does not count for -lead, -count, the context rows and the line numbers
+	lead := f.lead
+	f.measuring = true
+	if fname != "" && ld.IsSeekable(fname) {
+		// first pass over the whole file for the column widths
+		pass := make(chan string, 1024)
+		go ld.StreamFile(fname, pass)
+		first := true
+		for line := range pass {
+			f.measure([]string{line}, first)
+			first = false
+		}
+	} else {
+		f.measure(sample, true)
+	}
+	f.lead, f.matches, f.before, f.after = lead, T_matches{}, nil, 0
+	f.line, f.measuring = 0, false
+
+	f.start()
+	for _, line := range sample {
+		f.add(line)
+	}
+	for line := range lines {
+		f.add(line)
+	}
+	if ap.CmdParams.Pp {
+		f.print(f.trenner("-"))
+	}
+	f.footer()
+}
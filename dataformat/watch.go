@@ -0,0 +1,159 @@
+package pc
+
+import (
+	"fmt"
+	ap "pc/argparse"
+	ld "pc/loaddata"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ANSI escape codes of the watch mode
+const (
+	clearScreen = "\033[H\033[2J" // move the cursor home and clear the screen
+	changedCell = "\033[7m"       // reverse video for cells, that changed since the last refresh
+	addedRow    = "\033[32m"      // green for rows, that are new since the last refresh
+	resetColor  = "\033[0m"
+)
+
+// T_watchRows holds the data rows of one refresh by their key
+type T_watchRows map[string]T_dataline
+
+// T_watchStats counts the differences to the previous refresh
+type T_watchStats struct {
+	Rows    int
+	Added   int
+	Removed int
+	Changed int // number of changed cells in rows, that exist in both refreshes
+}
+
+// String returns the counts for the status line
+func (s T_watchStats) String() string {
+	return fmt.Sprintf("%d rows, %d added, %d removed, %d cells changed", s.Rows, s.Added, s.Removed, s.Changed)
+}
+
+// watchKeys returns the key of each row, the value of the -key column.
+// Repeated values get a suffix with their occurrence, so every key is unique.
+func watchKeys(rows T_parsedData) []string {
+	col := int(ap.CmdParams.Key) - 1
+	seen := map[string]int{}
+	keys := make([]string, len(rows))
+	for i, row := range rows {
+		key := strings.Join(row, "\t") // the whole row, if there is no such column
+		if col >= 0 && col < len(row) {
+			key = row[col]
+		}
+		if seen[key]++; seen[key] > 1 {
+			key += "#" + strconv.Itoa(seen[key])
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// colorCell wraps every visual line of a formatted cell into the ANSI color code
+func colorCell(cell, color string) string {
+	lines := strings.Split(cell, "\n")
+	for i, l := range lines {
+		lines[i] = color + l + resetColor
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WatchFormat prints the data as ASCII table like Format and colors the cells, that changed
+// since the previous refresh, and the rows, that are new. Rows are matched by the -key column.
+// Without previous rows nothing is colored. The rows of this refresh are returned together
+// with the counts of the differences.
+func WatchFormat(data T_parsedData, prev T_watchRows) (T_watchRows, T_watchStats) {
+	if err := data.arrange(&ap.CmdParams); err != nil {
+		ap.Fail(err)
+	}
+	first := 0 // number of header rows
+	if ap.CmdParams.Header != "" || !(ap.CmdParams.Nhl || ap.CmdParams.Rh) {
+		first = min(1, len(data))
+	}
+
+	rows := T_watchRows{}
+	keys := watchKeys(data[first:])
+	for i, key := range keys {
+		rows[key] = append(T_dataline{}, data[first+i]...) // a copy, the cells are padded below
+	}
+	stats := T_watchStats{Rows: len(keys)}
+	if prev != nil {
+		for key := range prev {
+			if _, ok := rows[key]; !ok {
+				stats.Removed++
+			}
+		}
+	}
+
+	maxlen := GetMaxLength(data)
+	trenner := make([]string, len(maxlen))
+	htrenner := make([]string, len(maxlen))
+	for i, v := range maxlen {
+		trenner[i] = strings.Repeat("-", v)
+		htrenner[i] = strings.Repeat("=", v)
+	}
+	// compare before the cells are padded to the column width
+	colors := make([][]string, len(keys))
+	for i, key := range keys {
+		if prev == nil {
+			continue
+		}
+		row := data[first+i]
+		colors[i] = make([]string, len(row))
+		old, ok := prev[key]
+		if !ok {
+			stats.Added++
+		}
+		for col := range row {
+			switch {
+			case !ok:
+				colors[i][col] = addedRow
+			case col >= len(old) || old[col] != row[col]:
+				colors[i][col] = changedCell
+				stats.Changed++
+			}
+		}
+	}
+	if !ap.CmdParams.Nf {
+		data.formatDataToMaxWidth(NewColumns(data, maxlen))
+	}
+	for i, rowColors := range colors {
+		for col, color := range rowColors {
+			if color != "" {
+				data[first+i][col] = colorCell(data[first+i][col], color)
+			}
+		}
+	}
+	data.insertTrenner(&ap.CmdParams, trenner, htrenner)
+	data.PrintAsciiTab(maxlen)
+	return rows, stats
+}
+
+// Watch runs the command every interval, parses its output and redraws the table in place.
+// Changed cells are highlighted, a status line shows the time and the counts of added and
+// removed rows. It runs until the program is terminated, e.g. by Ctrl-C.
+func Watch(command []string, interval time.Duration) {
+	var prev T_watchRows
+	for {
+		src, err := ld.RunCommand(command)
+		if prev == nil && ap.CmdParams.Auto {
+			ApplyFormat(DetectFormat(T_rawdata(src.Lines), SniffLines))
+		}
+		sep := []rune(ap.CmdParams.Sep)[0]
+		data := SourcesParse([]ld.T_source{src}, sep)
+
+		fmt.Print(clearScreen)
+		rows, stats := WatchFormat(data, prev)
+		status := fmt.Sprintf("Every %s: %s    %s    %s", interval, src.Name, time.Now().Format("15:04:05"), stats)
+		if err != nil {
+			status += "    ERROR: " + err.Error()
+		}
+		fmt.Println()
+		fmt.Println(status)
+		prev = rows
+		time.Sleep(interval)
+	}
+}
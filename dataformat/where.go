@@ -0,0 +1,419 @@
+package pc
+
+import (
+	"fmt"
+	ap "pc/argparse"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The -where expression selects rows by conditions on their columns, e.g.
+//
+//	status != "Running" && restarts > 3 || name ~ /^api-/
+//
+// A column is referenced by its title in the headline or by its number like $3.
+// Values are compared as numbers, if both sides are numbers, otherwise as text.
+// ~ and !~ match a regex given as /regex/ or as string. Conditions are combined by
+// && (and), || (or), ! (not) and parentheses. A column alone is true, if it is not empty.
+//
+// The expression is parsed once into a tree of t_whereNode, that is evaluated for every row.
+
+// t_whereNode is a node of the parsed -where expression
+type t_whereNode interface {
+	eval(row T_dataline) bool
+}
+
+// t_whereOperand is a column reference or a literal value
+type t_whereOperand struct {
+	col int // index of the column, -1 for a literal
+	val string
+}
+
+// value returns the value of the operand for the row, a missing column is empty
+func (o t_whereOperand) value(row T_dataline) string {
+	if o.col < 0 {
+		return o.val
+	}
+	if o.col < len(row) {
+		return row[o.col]
+	}
+	return ""
+}
+
+type (
+	t_whereAnd      struct{ l, r t_whereNode }
+	t_whereOr       struct{ l, r t_whereNode }
+	t_whereNot      struct{ n t_whereNode }
+	t_whereNonEmpty struct{ o t_whereOperand }
+	t_whereCompare  struct {
+		l, r t_whereOperand
+		op   string
+	}
+	t_whereMatch struct {
+		l   t_whereOperand
+		re  *regexp.Regexp
+		not bool
+	}
+)
+
+func (n t_whereAnd) eval(row T_dataline) bool      { return n.l.eval(row) && n.r.eval(row) }
+func (n t_whereOr) eval(row T_dataline) bool       { return n.l.eval(row) || n.r.eval(row) }
+func (n t_whereNot) eval(row T_dataline) bool      { return !n.n.eval(row) }
+func (n t_whereNonEmpty) eval(row T_dataline) bool { return strings.TrimSpace(n.o.value(row)) != "" }
+func (n t_whereMatch) eval(row T_dataline) bool    { return n.re.MatchString(n.l.value(row)) != n.not }
+
+// eval compares the values as numbers, if both are numbers, otherwise as text
+func (n t_whereCompare) eval(row T_dataline) bool {
+	a, b := n.l.value(row), n.r.value(row)
+	c := strings.Compare(a, b)
+	fa, erra := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errb := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if erra == nil && errb == nil {
+		switch {
+		case fa < fb:
+			c = -1
+		case fa > fb:
+			c = 1
+		default:
+			c = 0
+		}
+	}
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0 // ">="
+}
+
+// T_where is a parsed -where expression
+type T_where struct {
+	root t_whereNode
+}
+
+// Match returns true, if the row fulfills the expression
+func (w *T_where) Match(row T_dataline) bool {
+	return w.root.eval(row)
+}
+
+// t_whereToken is a token of the expression with its position in characters
+type t_whereToken struct {
+	kind string // "op", "col", "name", "str", "num", "re" or "" at the end
+	val  string
+	pos  int
+}
+
+// whereOperators are the operators, the two letter operators first
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "!~", "<", ">", "~", "!", "(", ")", "="}
+
+// whereWords are the words, that are operators instead of column names
+var whereWords = map[string]string{"and": "&&", "or": "||", "not": "!"}
+
+// whereRunes returns the end of the runs of runes from start on, that fulfill ok
+func whereRunes(expr string, start int, ok func(rune) bool) int {
+	for start < len(expr) {
+		r, size := utf8.DecodeRuneInString(expr[start:])
+		if !ok(r) {
+			break
+		}
+		start += size
+	}
+	return start
+}
+
+// whereTokens splits the expression into tokens. Column titles may contain all unicode letters.
+func whereTokens(expr string) ([]t_whereToken, error) {
+	tokens := []t_whereToken{}
+	for i := 0; i < len(expr); {
+		c, size := utf8.DecodeRuneInString(expr[i:])
+		pos := utf8.RuneCountInString(expr[:i]) // position in characters for the messages
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '"' || c == '\'' || c == '/':
+			val, n, err := whereQuoted(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%v at position %d", err, pos+1)
+			}
+			kind := "str"
+			if c == '/' {
+				kind = "re"
+			}
+			tokens = append(tokens, t_whereToken{kind, val, pos})
+			i += n
+		case c == '$':
+			j := whereRunes(expr, i+1, unicode.IsDigit)
+			if j == i+1 {
+				return nil, fmt.Errorf("column number expected after $ at position %d", pos+1)
+			}
+			tokens = append(tokens, t_whereToken{"col", expr[i+1 : j], pos})
+			i = j
+		case unicode.IsDigit(c) || c == '-' || c == '.':
+			j := whereRunes(expr, i+1, func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+			if _, err := strconv.ParseFloat(expr[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", expr[i:j], pos+1)
+			}
+			tokens = append(tokens, t_whereToken{"num", expr[i:j], pos})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := whereRunes(expr, i+size, func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r)
+			})
+			if op, ok := whereWords[strings.ToLower(expr[i:j])]; ok {
+				tokens = append(tokens, t_whereToken{"op", op, pos})
+			} else {
+				tokens = append(tokens, t_whereToken{"name", expr[i:j], pos})
+			}
+			i = j
+		default:
+			op := ""
+			for _, o := range whereOperators {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", c, pos+1)
+			}
+			n := len(op)
+			if op == "=" {
+				op = "=="
+			}
+			tokens = append(tokens, t_whereToken{"op", op, pos})
+			i += n
+		}
+	}
+	return append(tokens, t_whereToken{pos: utf8.RuneCountInString(expr)}), nil
+}
+
+// whereQuoted returns the text between the quotes at the start of s and the length of the
+// quoted text. A backslash escapes the quote, other escapes are kept for regexes.
+func whereQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var val strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == quote:
+			val.WriteByte(quote)
+			i++
+		case s[i] == quote:
+			return val.String(), i + 1, nil
+		default:
+			val.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("missing closing %c", quote)
+}
+
+// t_whereParser is a recursive descent parser of the -where expression
+type t_whereParser struct {
+	tokens []t_whereToken
+	next   int
+	header T_dataline
+}
+
+func (p *t_whereParser) peek() t_whereToken { return p.tokens[p.next] }
+func (p *t_whereParser) take() t_whereToken {
+	t := p.tokens[p.next]
+	if t.kind != "" {
+		p.next++
+	}
+	return t
+}
+
+// isOp returns true and takes the token, if the next token is the operator
+func (p *t_whereParser) isOp(op string) bool {
+	if t := p.peek(); t.kind == "op" && t.val == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+// fail returns an error for the next token
+func (p *t_whereParser) fail(expected string) error {
+	t := p.peek()
+	if t.kind == "" {
+		return fmt.Errorf("%s expected at the end", expected)
+	}
+	return fmt.Errorf("%s expected at position %d, found %q", expected, t.pos+1, t.val)
+}
+
+// or = and { "||" and }
+func (p *t_whereParser) or() (t_whereNode, error) {
+	l, err := p.and()
+	for err == nil && p.isOp("||") {
+		var r t_whereNode
+		if r, err = p.and(); err == nil {
+			l = t_whereOr{l, r}
+		}
+	}
+	return l, err
+}
+
+// and = not { "&&" not }
+func (p *t_whereParser) and() (t_whereNode, error) {
+	l, err := p.not()
+	for err == nil && p.isOp("&&") {
+		var r t_whereNode
+		if r, err = p.not(); err == nil {
+			l = t_whereAnd{l, r}
+		}
+	}
+	return l, err
+}
+
+// not = "!" not | "(" or ")" | condition
+func (p *t_whereParser) not() (t_whereNode, error) {
+	if p.isOp("!") {
+		n, err := p.not()
+		return t_whereNot{n}, err
+	}
+	if p.isOp("(") {
+		n, err := p.or()
+		if err == nil && !p.isOp(")") {
+			err = p.fail("')'")
+		}
+		return n, err
+	}
+	return p.condition()
+}
+
+// condition = operand [ op operand | ("~" | "!~") regex ]
+func (p *t_whereParser) condition() (t_whereNode, error) {
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != "op" {
+		return t_whereNonEmpty{l}, nil
+	}
+	switch t.val {
+	case "~", "!~":
+		p.take()
+		r := p.take()
+		if r.kind != "re" && r.kind != "str" {
+			if r.kind != "" {
+				p.next--
+			}
+			return nil, p.fail("regex")
+		}
+		re, err := regexp.Compile(r.val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex at position %d: %v", r.pos+1, err)
+		}
+		return t_whereMatch{l, re, t.val == "!~"}, nil
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.take()
+		r, err := p.operand()
+		return t_whereCompare{l, r, t.val}, err
+	}
+	return t_whereNonEmpty{l}, nil
+}
+
+// operand = column | string | number
+func (p *t_whereParser) operand() (t_whereOperand, error) {
+	t := p.take()
+	switch t.kind {
+	case "str", "num":
+		return t_whereOperand{col: -1, val: t.val}, nil
+	case "col":
+		n, _ := strconv.Atoi(t.val)
+		if n < 1 {
+			return t_whereOperand{}, fmt.Errorf("column $%s at position %d does not exist", t.val, t.pos+1)
+		}
+		return t_whereOperand{col: n - 1}, nil
+	case "name":
+		col := p.column(t.val)
+		if col < 0 {
+			return t_whereOperand{}, fmt.Errorf("column %q at position %d is not in the headline", t.val, t.pos+1)
+		}
+		return t_whereOperand{col: col}, nil
+	}
+	if t.kind != "" {
+		p.next--
+	}
+	return t_whereOperand{}, p.fail("column or value")
+}
+
+// column returns the index of the column with the title, an exact match first,
+// otherwise ignoring the case. It returns -1 for unknown titles.
+func (p *t_whereParser) column(name string) int {
+	for i, title := range p.header {
+		if title == name {
+			return i
+		}
+	}
+	for i, title := range p.header {
+		if strings.EqualFold(title, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseWhere parses the -where expression. Column titles are looked up in the header.
+// Errors are of class ErrParam with the position in the expression.
+func ParseWhere(expr string, header T_dataline) (*T_where, error) {
+	tokens, err := whereTokens(expr)
+	if err == nil {
+		p := &t_whereParser{tokens: tokens, header: header}
+		var root t_whereNode
+		if root, err = p.or(); err == nil && p.peek().kind != "" {
+			err = p.fail("operator")
+		}
+		if err == nil {
+			return &T_where{root: root}, nil
+		}
+	}
+	return nil, ap.NewError(ap.ErrParam, "-where='%s': %v", expr, err)
+}
+
+// whereRows keeps the rows, that fulfill the -where expression, with -j in concurrent chunks.
+// The first rows, the headline and the -lead rows, are kept unfiltered. The titles of the
+// headline name the columns, unless -header defines them for an input without headline.
+func whereRows(p *ap.T_flags, rows T_parsedData, nums []int, texts []string, sep rune, first int) (T_parsedData, []int, []string, error) {
+	if p.Where == "" || len(rows) == 0 {
+		return rows, nums, texts, nil
+	}
+	var header T_dataline
+	if p.Header != "" {
+		header = lineParse(p.Header, sep, p.MoreBlanks)
+	} else if !p.Nhl {
+		header = rows[0]
+	}
+	w, err := ParseWhere(p.Where, header)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keep := make([]bool, len(rows))
+	for i := range first {
+		keep[i] = true
+	}
+	parallelChunks(p.Jobs, len(rows)-first, func(start, end int) {
+		for i := start + first; i < end+first; i++ {
+			keep[i] = w.Match(rows[i])
+		}
+	})
+	nd, nnums, ntexts := T_parsedData{}, []int{}, []string{}
+	for i, row := range rows {
+		if keep[i] {
+			nd = append(nd, row)
+			nnums = append(nnums, nums[i])
+			ntexts = append(ntexts, texts[i])
+		}
+	}
+	return nd, nnums, ntexts, nil
+}
@@ -4,11 +4,11 @@ import (
 	"os"
 )
 
-// checkStdin returns true if there are data on STDIN
+// checkStdin returns true if there are data on STDIN. A closed or invalid STDIN has no data.
 func checkStdin() bool {
 	fi, err := os.Stdin.Stat()
 	if err != nil {
-		panic(err)
+		return false
 	}
 	if fi.Mode()&os.ModeNamedPipe == 0 {
 		return false
@@ -0,0 +1,28 @@
+package pc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RunCommand runs the command line and returns its output as a source named like the command.
+// The output is returned also, if the command fails. Then the error contains the first line
+// the command printed to STDERR.
+func RunCommand(args []string) (T_source, error) {
+	src := T_source{Name: strings.Join(args, " ")}
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		msg, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+		err = fmt.Errorf("%v: %s", err, msg)
+	}
+	lines, rerr := readLines(bytes.NewReader(out))
+	if err == nil {
+		err = rerr
+	}
+	src.Lines = MergeQuotedLines(lines)
+	return src, err
+}
@@ -0,0 +1,111 @@
+package pc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// magic bytes at the start of compressed data
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	magicXz    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	magicZstd  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// magic bytes of the first bzip2 block and of the end of an empty bzip2 stream
+var (
+	magicBzip2Block = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	magicBzip2End   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// isBzip2 returns true, if head is the start of bzip2 data: 'BZh', the block size '1'..'9'
+// and the magic of the first block. Text starting with 'BZh' is not taken as bzip2.
+func isBzip2(head []byte) bool {
+	if len(head) < 10 || !bytes.HasPrefix(head, magicBzip2) || head[3] < '1' || head[3] > '9' {
+		return false
+	}
+	return bytes.Equal(head[4:10], magicBzip2Block) || bytes.Equal(head[4:10], magicBzip2End)
+}
+
+// T_decompressed is the uncompressed content of a reader.
+// Close must be called after reading, to wait for an external decoder.
+type T_decompressed struct {
+	io.Reader
+	cmd  *exec.Cmd
+	pipe *t_pipe
+}
+
+// t_pipe is the output of an external decoder, it records, if it was read up to the end
+type t_pipe struct {
+	io.ReadCloser
+	eof bool
+}
+
+func (p *t_pipe) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if err == io.EOF {
+		p.eof = true
+	}
+	return n, err
+}
+
+// Close waits for the external decoder, if there is one, and returns its error.
+// A decoder, whose output was not read up to the end, is stopped, as it would block
+// writing the rest of its output.
+func (d T_decompressed) Close() error {
+	if d.cmd == nil {
+		return nil
+	}
+	if !d.pipe.eof {
+		d.cmd.Process.Kill()
+		d.pipe.Close()
+		d.cmd.Wait()
+		return nil
+	}
+	return d.cmd.Wait()
+}
+
+// decodeExternal uncompresses the data with a locally installed decoder like xz or zstd
+func decodeExternal(r io.Reader, decoder string) (T_decompressed, error) {
+	path, err := exec.LookPath(decoder)
+	if err != nil {
+		return T_decompressed{}, fmt.Errorf("input is %s compressed, but no local '%s' decoder is found: %w", decoder, decoder, err)
+	}
+	cmd := exec.Command(path, "-dc")
+	cmd.Stdin = r
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return T_decompressed{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return T_decompressed{}, err
+	}
+	pipe := &t_pipe{ReadCloser: out}
+	return T_decompressed{Reader: pipe, cmd: cmd, pipe: pipe}, nil
+}
+
+// Decompress detects compressed data by its magic bytes and returns a reader for the uncompressed content.
+// gzip and bzip2 are decoded by the standard library, xz and zstd by the local 'xz' or 'zstd' command.
+// Uncompressed data is returned unchanged.
+func Decompress(r io.Reader) (T_decompressed, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(10)
+	switch {
+	case bytes.HasPrefix(head, magicGzip):
+		gz, err := gzip.NewReader(br)
+		return T_decompressed{Reader: gz}, err
+	case isBzip2(head):
+		return T_decompressed{Reader: bzip2.NewReader(br)}, nil
+	case bytes.HasPrefix(head, magicXz):
+		return decodeExternal(br, "xz")
+	case bytes.HasPrefix(head, magicZstd):
+		return decodeExternal(br, "zstd")
+	}
+	return T_decompressed{Reader: br}, nil
+}
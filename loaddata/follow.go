@@ -0,0 +1,73 @@
+package pc
+
+import (
+	"bufio"
+	"io"
+	"os"
+	ap "pc/argparse"
+	"strings"
+	"time"
+)
+
+// followPoll is the interval in which a followed file is checked for new data
+const followPoll = 200 * time.Millisecond
+
+// readNewLines sends all complete lines, that can be read now, to the channel.
+// An incomplete last line is kept in pending until its linefeed arrives.
+func readNewLines(r *bufio.Reader, pending *strings.Builder, lines chan<- string) error {
+	for {
+		chunk, err := r.ReadString('\n')
+		pending.WriteString(chunk)
+		if err != nil {
+			return err
+		}
+		lines <- strings.TrimSuffix(strings.TrimSuffix(pending.String(), "\n"), "\r")
+		pending.Reset()
+	}
+}
+
+// Follow sends the lines of a file to the channel like 'tail -f': after the existing content,
+// the file is polled for new lines until done is closed. If the file is truncated, e.g. by
+// a log rotation, it is read again from the start.
+// Without filename, STDIN is read line by line until EOF. The channel is closed at the end.
+func Follow(fname string, lines chan<- string, done <-chan struct{}) {
+	defer close(lines)
+	var pending strings.Builder
+	if fname == "" {
+		err := readNewLines(bufio.NewReader(os.Stdin), &pending, lines)
+		if pending.Len() > 0 {
+			lines <- pending.String()
+		}
+		if err != nil && err != io.EOF {
+			ap.Fail(ap.ErrorAt(ap.ErrInput, err, StdinName, 0, 0))
+		}
+		return
+	}
+
+	file, err := os.Open(fname)
+	if err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrInput, err, "", 0, 0))
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	var offset int64
+	for {
+		if err := readNewLines(r, &pending, lines); err != io.EOF {
+			ap.Fail(ap.ErrorAt(ap.ErrInput, err, fname, 0, 0))
+		}
+		if offset, err = file.Seek(0, io.SeekCurrent); err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrInput, err, fname, 0, 0))
+		}
+		select {
+		case <-done:
+			return
+		case <-time.After(followPoll):
+		}
+		// start again, if the file got shorter than the read position
+		if fi, err := file.Stat(); err == nil && fi.Size() < offset {
+			file.Seek(0, io.SeekStart)
+			r.Reset(file)
+			pending.Reset()
+		}
+	}
+}
@@ -2,14 +2,27 @@ package pc
 
 import (
 	"bufio"
-	"fmt"
-	"log"
+	"io"
 	"os"
 	"strings"
 
 	ap "pc/argparse"
 )
 
+// openQuote returns true, if the text ends inside a quoted field.
+// It counts the unescaped double quotes, an odd number means the quote is still open.
+func openQuote(s string) bool {
+	quoteCount := 0
+	prevChar := rune(0)
+	for _, ch := range s {
+		if ch == '"' && prevChar != '\\' {
+			quoteCount++
+		}
+		prevChar = ch
+	}
+	return (quoteCount % 2) != 0
+}
+
 // MergeQuotedLines takes raw lines and merges lines that are part of a quoted field
 // spanning multiple lines. A line is considered incomplete if it has an odd number
 // of unescaped double quotes.
@@ -27,18 +40,7 @@ func MergeQuotedLines(lines []string) []string {
 			pending.WriteString(line)
 		}
 
-		// Count unescaped quotes to determine if we're inside a quoted field
-		quoteCount := 0
-		prevChar := rune(0)
-		for _, ch := range pending.String() {
-			if ch == '"' && prevChar != '\\' {
-				quoteCount++
-			}
-			prevChar = ch
-		}
-
-		// Odd number of quotes means we're still inside a quoted field
-		inQuote = (quoteCount % 2) != 0
+		inQuote = openQuote(pending.String())
 
 		if !inQuote {
 			// Line is complete, add to result
@@ -55,17 +57,41 @@ func MergeQuotedLines(lines []string) []string {
 	return result
 }
 
+// maxLineLength is the longest input line the scanners accept, e.g. minified JSON documents
+const maxLineLength = 64 * 1024 * 1024
+
+// newScanner returns a line scanner, that accepts lines up to maxLineLength
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	return scanner
+}
+
+// readLines reads all lines from a reader, compressed data is uncompressed transparently.
+func readLines(r io.Reader) ([]string, error) {
+	data := []string{}
+	dr, err := Decompress(r)
+	if err != nil {
+		return data, err
+	}
+	scanner := newScanner(dr)
+	for scanner.Scan() {
+		data = append(data, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		dr.Close()
+		return data, err
+	}
+	return data, dr.Close()
+}
+
 // get StdinData read data from STDIN
 func getStdinData() []string {
 	data := []string{}
 	if checkStdin() {
-		// fmt.Println("Something on STDIN")
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			data = append(data, scanner.Text())
-		}
-		if err := scanner.Err(); err != nil {
-			log.Println(err)
+		var err error
+		if data, err = readLines(os.Stdin); err != nil {
+			ap.Fail(ap.ErrorAt(ap.ErrInput, err, StdinName, 0, 0))
 		}
 	}
 	return MergeQuotedLines(data)
@@ -73,41 +99,73 @@ func getStdinData() []string {
 
 // getFileData reads data from a file and returns it as a slice of strings.
 // It takes the filename as an input parameter.
+// Files compressed with gzip, bzip2, xz or zstd are uncompressed transparently.
 // If there's an error opening or reading the file, it logs a fatal error.
 func getFileData(fname string) []string {
-	data := []string{}
 	file, err := os.Open(fname)
 	if err != nil {
-		log.Fatal("Open File:"+fname, err)
+		ap.Fail(ap.ErrorAt(ap.ErrInput, err, "", 0, 0))
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		data = append(data, scanner.Text())
-	}
-	if err := scanner.Err(); err != nil {
-		log.Fatal("Read File:"+fname, err)
+	data, err := readLines(file)
+	if err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrInput, err, fname, 0, 0))
 	}
 	return MergeQuotedLines(data)
 }
 
+// StdinName is the source name of data read from STDIN
+const StdinName = "STDIN"
+
+// T_source holds the lines of one input, a file or STDIN
+type T_source struct {
+	Name  string
+	Lines []string
+}
+
+// GetSources reads all files defined by -file in the given order and then STDIN, if there is data.
+// Each input is returned as its own source, so the origin of every line is known.
+func GetSources() []T_source {
+	sources := []T_source{}
+	for _, fname := range ap.CmdParams.Files {
+		sources = append(sources, T_source{Name: fname, Lines: getFileData(fname)})
+	}
+
+	// Check if there's input from stdin, and if so, add it as last source
+	if checkStdin() {
+		sources = append(sources, T_source{Name: StdinName, Lines: getStdinData()})
+	}
+	return sources
+}
+
 // GetData reads data from a file and/or stdin, depending on the provided parameters.
+// If filename is empty, all files from the -file parameters are read.
 // It returns a slice of strings containing the read data.
 func GetData(filename string) []string {
 	data := []string{}
 
-	// If a filename is provided in the command parameters, read from the file
-	if ap.CmdParams.Filename != "" {
-		fmt.Println("GetData filename:", filename) // Debug print: show the filename being processed
-		data = getFileData(filename)               // Read data from the file
-		fmt.Println("GetData data:", data)         // Debug print: show the data read from the file
+	// If a filename is provided, read only this file and stdin
+	if filename != "" {
+		data = getFileData(filename)
+		if checkStdin() {
+			data = append(data, getStdinData()...)
+		}
+		return data
 	}
 
-	// Check if there's input from stdin, and if so, append it to the data
-	if checkStdin() {
-		data = append(data, getStdinData()...) // Append data from stdin to the existing data
+	for _, src := range GetSources() {
+		data = append(data, src.Lines...)
 	}
+	return data // Return the combined data from files and/or stdin
+}
 
-	return data // Return the combined data from file and/or stdin
+// ReadLines reads all lines from a reader like a file given by -file: compressed data is
+// uncompressed and lines of a quoted field spanning multiple lines are merged.
+func ReadLines(r io.Reader) ([]string, error) {
+	data, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	return MergeQuotedLines(data), nil
 }
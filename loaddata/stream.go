@@ -0,0 +1,67 @@
+package pc
+
+import (
+	"io"
+	"os"
+	ap "pc/argparse"
+	"strings"
+)
+
+// streamLines sends the lines of the reader to the channel without keeping them in memory.
+// Lines that are part of a quoted field spanning multiple lines are merged like MergeQuotedLines does.
+func streamLines(r io.Reader, lines chan<- string) error {
+	dr, err := Decompress(r)
+	if err != nil {
+		return err
+	}
+	var pending strings.Builder
+	inQuote := false
+	scanner := newScanner(dr)
+	for scanner.Scan() {
+		if inQuote {
+			pending.WriteString("\n")
+		}
+		pending.WriteString(scanner.Text())
+		// the quotes of the new line toggle the state of the merged line
+		if inQuote = inQuote != openQuote(scanner.Text()); !inQuote {
+			lines <- pending.String()
+			pending.Reset()
+		}
+	}
+	if pending.Len() > 0 {
+		lines <- pending.String()
+	}
+	if err := scanner.Err(); err != nil {
+		dr.Close()
+		return err
+	}
+	return dr.Close()
+}
+
+// StreamFile sends the lines of the file, or of STDIN without filename, one by one to the
+// channel and closes it at the end. Compressed data is uncompressed transparently.
+func StreamFile(fname string, lines chan<- string) {
+	defer close(lines)
+	if fname == "" {
+		if checkStdin() {
+			if err := streamLines(os.Stdin, lines); err != nil {
+				ap.Fail(ap.ErrorAt(ap.ErrInput, err, StdinName, 0, 0))
+			}
+		}
+		return
+	}
+	file, err := os.Open(fname)
+	if err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrInput, err, "", 0, 0))
+	}
+	defer file.Close()
+	if err := streamLines(file, lines); err != nil {
+		ap.Fail(ap.ErrorAt(ap.ErrInput, err, fname, 0, 0))
+	}
+}
+
+// IsSeekable returns true, if the file is a regular file, that can be read twice.
+func IsSeekable(fname string) bool {
+	fi, err := os.Stat(fname)
+	return err == nil && fi.Mode().IsRegular()
+}
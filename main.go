@@ -2,6 +2,7 @@
 package main
 
 import (
+	"os"
 	ap "pc/argparse"
 	df "pc/dataformat"
 	ld "pc/loaddata"
@@ -20,12 +21,44 @@ func main() {
 
 	ap.CmdParams.Sep = " "
 	ap.EvalFlags()
-	// Load data from file and/or STDIN
-	rawdata := df.T_rawdata(ld.GetData(""))
+	// Follow a growing file or STDIN and print the lines as they arrive
+	if ap.CmdParams.Follow {
+		lines := make(chan string)
+		fname := ""
+		if len(ap.CmdParams.Files) > 0 {
+			fname = ap.CmdParams.Files[0]
+		}
+		go ld.Follow(fname, lines, nil)
+		df.FollowFormat(lines, []rune(ap.CmdParams.Sep)[0])
+		return
+	}
+	// Run a command periodically and redraw its output
+	if ap.CmdParams.Watch > 0 {
+		df.Watch(ap.CmdParams.Command, ap.CmdParams.Watch)
+		return
+	}
+	// Print the rows as they are read, if nothing needs all rows in memory
+	if df.CanStream() {
+		fname := ""
+		if len(ap.CmdParams.Files) > 0 {
+			fname = ap.CmdParams.Files[0]
+		}
+		df.Stream(fname, []rune(ap.CmdParams.Sep)[0])
+		return
+	}
+	// Load data from files and/or STDIN
+	sources := ld.GetSources()
+	// Detect the input format, if not defined by parameters
+	if ap.CmdParams.Auto && len(sources) > 0 {
+		df.ApplyFormat(df.DetectFormat(df.T_rawdata(sources[0].Lines), df.SniffLines))
+	}
 	// Get the seperator for parsing the data input
 	sep := []rune(ap.CmdParams.Sep)[0]
 	//  parse the input data
-	pdata := df.DataParse(rawdata, sep)
+	pdata, matches := df.SourcesParseMatches(sources, sep)
 	// Format the parsed data and print out
 	df.Format(pdata)
+	if err := df.WriteMatches(os.Stdout, &ap.CmdParams, matches); err != nil {
+		ap.Fail(err)
+	}
 }
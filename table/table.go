@@ -0,0 +1,312 @@
+// Package table parses and renders text columns like the pc command does, for use in other
+// Go programs. It uses no global state: every call gets its Options, writes to the given
+// io.Writer and returns errors instead of exiting the program.
+//
+//	t, err := table.Parse(strings.NewReader("NAME AGE\nbob 42\n"), table.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	err = t.Render(os.Stdout, table.Options{PrettyPrint: true})
+package table
+
+import (
+	"io"
+	"slices"
+
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+)
+
+// Input formats of Options.Input
+const (
+	InputText   = ""       // lines split by Sep, MoreBlanks or fixed widths
+	InputCSV    = "csv"    // RFC 4180 CSV, Sep defaults to ','
+	InputTSV    = "tsv"    // tab separated values with CSV quoting
+	InputJSON   = "json"   // JSON array of objects or newline delimited JSON objects
+	InputLogfmt = "logfmt" // logfmt key=value pairs
+	InputStanza = "stanza" // blocks of 'Key: value' lines separated by blank lines
+)
+
+// Output formats of Options.Output
+const (
+	OutputTable = "" // aligned ASCII table
+	OutputCSV   = "csv"
+	OutputJSON  = "json"
+)
+
+// Error is the type of the errors returned by Parse and Render. Its Class tells, if the
+// options, the input or the output caused the error, Line and Column the position of
+// malformed input.
+type Error = ap.T_error
+
+// Classes of an Error
+const (
+	ErrParam  = ap.ErrParam  // wrong or conflicting options
+	ErrInput  = ap.ErrInput  // the input can not be read
+	ErrData   = ap.ErrData   // malformed input data
+	ErrOutput = ap.ErrOutput // the output can not be written
+)
+
+// Options define how the input is parsed and how the table is rendered. The zero value parses
+// blank separated columns with a headline and renders a plain ASCII table, like pc without
+// parameters. The name of the corresponding pc parameter is given in brackets.
+type Options struct {
+	// Parsing
+	Sep        rune     // separator of the input columns, default ' ' (-sep)
+	MoreBlanks bool     // only two or more blanks separate the columns (-mb)
+	Fixed      bool     // split the columns at the start positions of the headline fields (-fixed)
+	Widths     []int    // display widths of fixed-width input columns (-widths)
+	Input      string   // input format, one of the Input constants (-icsv, -itsv, -ijson, ...)
+	JsonArrays string   // arrays in JSON input: join, index or json, default join (-jarr)
+	Pattern    string   // regex with capture groups, the groups become the columns (-pattern)
+	NoMatch    string   // lines not matching Pattern: drop, keep or append, default drop (-nomatch)
+	NoHeadline bool     // the first line is data, not a headline (-nhl)
+	Filter     []string // only rows matching all regexes, 'n=regex' matches column n only (-filter)
+	FilterInv  bool     // only rows not matching the Filter (-fv)
+	FilterCase bool     // match the Filter case-insensitive (-fi)
+	After      int      // rows after a Filter match kept as context (-A)
+	Before     int      // rows before a Filter match kept as context (-B)
+	Where      string   // only rows fulfilling the expression like 'restarts > 3 && name ~ /^api-/' (-where)
+	Lead       int      // leading rows after the headline, that Filter and Where never drop (-lead)
+	Jobs       int      // concurrent workers parsing large inputs, default 1 (-j)
+	Strict     bool     // malformed rows are an error instead of being handled leniently (-strict)
+	Ragged     string   // rows with more or less fields than the headline: pad, truncate, merge-last, error or report (-ragged)
+
+	// Rendering
+	Output       string   // output format, one of the Output constants (-csv, -json)
+	Header       string   // headline for data without one, split like the input (-header)
+	Columns      []int    // output columns by number, starting with 1 (column arguments)
+	Rename       string   // new titles of the output columns like '2=Pod,STATUS=State' (-rename)
+	Select       []string // output columns by title, /regex/, '!' exclusion, negative number or open range, replaces Columns (column arguments)
+	SortCol      int      // sort the rows by this column (-sortcol)
+	GroupCol     int      // separator line, when the value of this column changes (-gcol)
+	GroupValues  bool     // keep repeated values in GroupCol (-gcolval)
+	RemoveHeader bool     // remove the first line (-rh)
+	Number       bool     // insert the column numbers as first line (-num)
+	TitleSep     bool     // separator line below the headline (-ts)
+	FooterSep    bool     // separator line above the last line (-fs)
+	PrettyPrint  bool     // cell borders and all separators (-pp)
+	ColumnSep    bool     // separator between the columns (-cs)
+	ColSep       string   // column separator, default '|' (-colsep)
+	ColSepWidth  int      // blanks around the column separator, default 1 (-w)
+	NoFormat     bool     // do not pad the columns to a common width (-nf)
+	NoNumbers    bool     // do not right adjust numbers (-nn)
+	Mark         string   // color lines matching the regex (-mark)
+	JsonFirstKey bool     // JSON output with the first column as key (-jtc)
+	Count        bool     // footer 'N of M rows matched' with Matched and Total of the table (-count)
+}
+
+// flags converts the options into the parameters used by the dataformat package and checks them
+func (o Options) flags() (*ap.T_flags, error) {
+	p := &ap.T_flags{
+		Sep:        " ",
+		Colsep:     "|",
+		ColSepW:    1,
+		Jarr:       "join",
+		NoMatch:    "drop",
+		Jobs:       1,
+		Sample:     1000,
+		SortMem:    256,
+		Key:        1,
+		MoreBlanks: o.MoreBlanks,
+		Fixed:      o.Fixed,
+		Widths:     o.Widths,
+		Pattern:    o.Pattern,
+		Nhl:        o.NoHeadline,
+		Filter:     o.Filter,
+		FilterInv:  o.FilterInv,
+		FilterCase: o.FilterCase,
+		After:      o.After,
+		Before:     o.Before,
+		Where:      o.Where,
+		Lead:       o.Lead,
+		Rename:     o.Rename,
+		Count:      o.Count,
+		Strict:     o.Strict,
+		Ragged:     o.Ragged,
+		Header:     o.Header,
+		SortCol:    ap.T_ColNum(o.SortCol),
+		Gcol:       ap.T_ColNum(o.GroupCol),
+		GcolVal:    o.GroupValues,
+		Rh:         o.RemoveHeader,
+		Num:        o.Number,
+		Ts:         o.TitleSep,
+		Fs:         o.FooterSep,
+		Pp:         o.PrettyPrint,
+		Cs:         o.ColumnSep,
+		Nf:         o.NoFormat,
+		Nn:         o.NoNumbers,
+		Mark:       o.Mark,
+		Jtc:        o.JsonFirstKey,
+	}
+	if o.Sep != 0 {
+		p.Sep = string(o.Sep)
+	}
+	if o.ColSep != "" {
+		p.Colsep = o.ColSep
+	}
+	if o.ColSepWidth > 0 {
+		p.ColSepW = o.ColSepWidth
+	}
+	if o.JsonArrays != "" {
+		p.Jarr = o.JsonArrays
+	}
+	if o.NoMatch != "" {
+		p.NoMatch = o.NoMatch
+	}
+	if o.Jobs > 0 {
+		p.Jobs = o.Jobs
+	}
+	for _, c := range o.Columns {
+		p.Columns = append(p.Columns, ap.T_ColNum(c))
+	}
+	p.ColSpecs = o.Select
+	switch o.Input {
+	case InputText:
+	case InputCSV:
+		p.Icsv = true
+	case InputTSV:
+		p.Itsv = true
+	case InputJSON:
+		p.Ijson = true
+	case InputLogfmt:
+		p.Ilogfmt = true
+	case InputStanza:
+		p.Istanza = true
+	default:
+		return nil, ap.NewError(ap.ErrParam, "input format %q is unknown, use csv, tsv, json, logfmt or stanza", o.Input)
+	}
+	switch o.Output {
+	case OutputTable:
+	case OutputCSV:
+		p.Csv = true
+	case OutputJSON:
+		p.Json = true
+	default:
+		return nil, ap.NewError(ap.ErrParam, "output format %q is unknown, use csv or json", o.Output)
+	}
+	if err := ap.Check(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// OptionsFromFlags returns the options defined by the parameters of the pc command line
+func OptionsFromFlags(p ap.T_flags) Options {
+	o := Options{
+		MoreBlanks:   p.MoreBlanks,
+		Fixed:        p.Fixed,
+		Widths:       p.Widths,
+		JsonArrays:   p.Jarr,
+		Pattern:      p.Pattern,
+		NoMatch:      p.NoMatch,
+		NoHeadline:   p.Nhl,
+		Filter:       p.Filter,
+		FilterInv:    p.FilterInv,
+		FilterCase:   p.FilterCase,
+		After:        p.After,
+		Before:       p.Before,
+		Where:        p.Where,
+		Lead:         p.Lead,
+		Jobs:         p.Jobs,
+		Strict:       p.Strict,
+		Ragged:       p.Ragged,
+		Header:       p.Header,
+		SortCol:      int(p.SortCol),
+		GroupCol:     int(p.Gcol),
+		GroupValues:  p.GcolVal,
+		RemoveHeader: p.Rh,
+		Number:       p.Num,
+		TitleSep:     p.Ts,
+		FooterSep:    p.Fs,
+		PrettyPrint:  p.Pp,
+		ColumnSep:    p.Cs,
+		ColSep:       p.Colsep,
+		ColSepWidth:  p.ColSepW,
+		NoFormat:     p.Nf,
+		NoNumbers:    p.Nn,
+		Mark:         p.Mark,
+		JsonFirstKey: p.Jtc,
+		Count:        p.Count,
+		Rename:       p.Rename,
+	}
+	if p.Sep != "" {
+		o.Sep = []rune(p.Sep)[0]
+	}
+	for _, c := range p.Columns {
+		o.Columns = append(o.Columns, int(c))
+	}
+	o.Select = p.ColSpecs
+	switch {
+	case p.Icsv:
+		o.Input = InputCSV
+	case p.Itsv:
+		o.Input = InputTSV
+	case p.Ijson:
+		o.Input = InputJSON
+	case p.Ilogfmt:
+		o.Input = InputLogfmt
+	case p.Istanza:
+		o.Input = InputStanza
+	}
+	switch {
+	case p.Csv:
+		o.Output = OutputCSV
+	case p.Json:
+		o.Output = OutputJSON
+	}
+	return o
+}
+
+// Table holds the rows of a table, the first row is the headline unless NoHeadline is set
+type Table struct {
+	Rows    [][]string
+	Matched int // rows selected by Filter and Where of Parse
+	Total   int // rows checked by Filter and Where of Parse, without headline and Lead rows
+}
+
+// New returns a table of the rows
+func New(rows [][]string) *Table {
+	return &Table{Rows: rows}
+}
+
+// Parse reads all lines from r and parses them into a table as defined by the parsing options.
+// Compressed input is uncompressed like the files read by pc.
+func Parse(r io.Reader, opts Options) (*Table, error) {
+	p, err := opts.flags()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := ld.ReadLines(r)
+	if err != nil {
+		return nil, ap.ErrorAt(ap.ErrInput, err, "", 0, 0)
+	}
+	data, m, err := df.ParseLinesMatches(df.T_rawdata(lines), p)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Rows: make([][]string, len(data)), Matched: m.Matched, Total: m.Total}
+	for i, row := range data {
+		t.Rows[i] = row
+	}
+	return t, nil
+}
+
+// Render writes the table to w as defined by the rendering options.
+// With Count the footer follows the table, for CSV and JSON output it is written to STDERR.
+// The rows of the table are not changed.
+func (t *Table) Render(w io.Writer, opts Options) error {
+	p, err := opts.flags()
+	if err != nil {
+		return err
+	}
+	data := make(df.T_parsedData, len(t.Rows))
+	for i, row := range t.Rows {
+		data[i] = slices.Clone(row)
+	}
+	if err := df.FormatTo(w, data, p); err != nil {
+		return err
+	}
+	return df.WriteMatches(w, p, df.T_matches{Matched: t.Matched, Total: t.Total})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"testing"
+)
+
+func TestColumnsRoundTripRaggedRows(t *testing.T) {
+	data := df.T_parsedData{
+		{"NAME", "SIZE", "NOTE"},
+		{"a", "12Mi"},
+		{"bb", "3", "multi\nline cell", "extra"},
+		{},
+	}
+	store := df.ToColumns(data)
+	if len(store.Cols) != 4 || store.Rows != 4 {
+		t.Fatalf("ToColumns() has %d columns and %d rows, want 4 and 4", len(store.Cols), store.Rows)
+	}
+	if got := store.ToRows(); !reflect.DeepEqual(got, data) {
+		t.Fatalf("ToRows() = %q, want %q", got, data)
+	}
+	if got, want := store.Widths(), df.GetMaxLength(data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Widths() = %v, want %v like GetMaxLength", got, want)
+	}
+}
+
+func TestColumnsSelectAndStats(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	data := df.T_parsedData{
+		{"NAME", "SIZE", "EMPTY"},
+		{"a", "12Mi", ""},
+		{"b", "3", ""},
+		{"a", "1,5", ""},
+	}
+	store := df.ToColumns(data)
+	sel := store.Select(ap.T_ColNumbers{2, 1, 7})
+	want := df.T_parsedData{
+		{"SIZE", "NAME", ""},
+		{"12Mi", "a", ""},
+		{"3", "b", ""},
+		{"1,5", "a", ""},
+	}
+	if got := sel.ToRows(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Select() = %q, want %q", got, want)
+	}
+
+	name := store.Cols[0].Stats()
+	if name != (df.T_colstats{Width: 4, Distinct: 3}) {
+		t.Fatalf("Stats() = %+v", name)
+	}
+	body := df.ToColumns(data[1:])
+	kinds := []df.T_colkind{body.Cols[0].Kind(), body.Cols[1].Kind(), body.Cols[2].Kind()}
+	if !reflect.DeepEqual(kinds, []df.T_colkind{df.KindText, df.KindNumeric, df.KindEmpty}) {
+		t.Fatalf("Kind() = %v", kinds)
+	}
+	layout := body.Layout()
+	if !layout[1].Numeric || layout[0].Numeric || layout[1].Width != 4 {
+		t.Fatalf("Layout() = %+v", layout)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var columnsHeader = []string{"NAME", "READY", "STATUS", "RESTARTS", "cpu_user", "cpu_sys"}
+
+func TestResolveColumns(t *testing.T) {
+	tests := []struct {
+		specs []string
+		want  ap.T_ColNumbers
+	}{
+		{[]string{"NAME", "STATUS"}, ap.T_ColNumbers{1, 3}},
+		{[]string{"status", "name"}, ap.T_ColNumbers{3, 1}},
+		{[]string{"/^cpu_/"}, ap.T_ColNumbers{5, 6}},
+		{[]string{"!3"}, ap.T_ColNumbers{1, 2, 4, 5, 6}},
+		{[]string{"!/^cpu_/", "!READY"}, ap.T_ColNumbers{1, 3, 4}},
+		{[]string{"-2"}, ap.T_ColNumbers{5}},
+		{[]string{"NAME", "-1"}, ap.T_ColNumbers{1, 6}},
+		{[]string{"4:"}, ap.T_ColNumbers{4, 5, 6}},
+		{[]string{":3"}, ap.T_ColNumbers{1, 2, 3}},
+		{[]string{"-1:-3"}, ap.T_ColNumbers{6, 5, 4}},
+		{[]string{"1:4", "!2:3"}, ap.T_ColNumbers{1, 4}},
+		{[]string{"2", "NAME"}, ap.T_ColNumbers{2, 1}},
+	}
+	for _, tt := range tests {
+		got, err := ap.ResolveColumns(tt.specs, columnsHeader, len(columnsHeader))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ResolveColumns(%q) = %v, %v, want %v", tt.specs, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveColumnsErrors(t *testing.T) {
+	tests := []struct {
+		specs  []string
+		header []string
+		want   string
+	}{
+		{[]string{"FOO"}, columnsHeader, "available are: NAME, READY, STATUS, RESTARTS, cpu_user, cpu_sys"},
+		{[]string{"-7"}, columnsHeader, "out of range"},
+		{[]string{"/^mem/"}, columnsHeader, "select no column"},
+		{[]string{"/(/"}, columnsHeader, "valid regex"},
+		{[]string{"NAME"}, nil, "needs a headline"},
+	}
+	for _, tt := range tests {
+		_, err := ap.ResolveColumns(tt.specs, tt.header, len(columnsHeader))
+		var pe *ap.T_error
+		if !errors.As(err, &pe) || pe.Class != ap.ErrParam || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ResolveColumns(%q) error = %v, want a parameter error with %q", tt.specs, err, tt.want)
+		}
+	}
+}
+
+func TestColumnArgs(t *testing.T) {
+	fs := flag.NewFlagSet("pc", flag.ContinueOnError)
+	fs.Bool("mb", false, "")
+	fs.String("null", "", "")
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-2"}, []string{"--", "-2"}},
+		{[]string{"-mb", "-1", "NAME"}, []string{"-mb", "--", "-1", "NAME"}},
+		{[]string{"-mb", "-3:"}, []string{"-mb", "--", "-3:"}},
+		{[]string{"-null", "-1", "2"}, []string{"-null", "-1", "2"}},
+		{[]string{"-null=-", "-1"}, []string{"-null=-", "--", "-1"}},
+		{[]string{"1", "-2"}, []string{"1", "-2"}},
+		{[]string{"--", "-1"}, []string{"--", "-1"}},
+		{[]string{"-mb", "2:5"}, []string{"-mb", "2:5"}},
+	}
+	for _, tt := range tests {
+		if erg := ap.ColumnArgs(fs, tt.args); !reflect.DeepEqual(erg, tt.want) {
+			t.Errorf("ColumnArgs(%q) = %q, want %q", tt.args, erg, tt.want)
+		}
+	}
+}
+
+func TestSelectColumnsByTitle(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.ColSpecs = []string{"AGE", "!/^N/", "-1"}
+	out := captureOutput(func() { df.Format(df.DataParse(df.T_rawdata{"NAME AGE CITY", "bob 42 Rome"}, ' ')) })
+	if want := "AGE CITY\n 42 Rome\n"; out != want {
+		t.Fatalf("Format() = %q, want %q", out, want)
+	}
+
+	var buf bytes.Buffer
+	tab := table.New([][]string{{"NAME", "AGE"}, {"bob", "42"}})
+	if err := tab.Render(&buf, table.Options{Output: table.OutputCSV, Select: []string{"age", "!age", "name"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME\nbob\n"; buf.String() != want {
+		t.Fatalf("Render() = %q, want %q", buf.String(), want)
+	}
+	if err := tab.Render(&buf, table.Options{Select: []string{"CITY"}}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Fatalf("Render() with an unknown title error = %v, want a parameter error", err)
+	}
+}
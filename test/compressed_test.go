@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ap "pc/argparse"
+	ld "pc/loaddata"
+)
+
+func TestDecompressGzipStream(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("a b\nc d\n"))
+	zw.Close()
+
+	r, err := ld.Decompress(&buf)
+	if err != nil {
+		t.Fatalf("Decompress() error: %v", err)
+	}
+	content, _ := io.ReadAll(r)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if string(content) != "a b\nc d\n" {
+		t.Fatalf("Decompress() = %q, want %q", content, "a b\nc d\n")
+	}
+}
+
+func TestDecompressPlainText(t *testing.T) {
+	r, err := ld.Decompress(bytes.NewBufferString("plain"))
+	if err != nil {
+		t.Fatalf("Decompress() error: %v", err)
+	}
+	content, _ := io.ReadAll(r)
+	if string(content) != "plain" {
+		t.Fatalf("Decompress() = %q, want %q", content, "plain")
+	}
+}
+
+func TestDecompressTextLikeBzip2(t *testing.T) {
+	for _, text := range []string{"BZh", "BZh9", "BZh is not bzip2\n", "BZh91AY&SX\n"} {
+		r, err := ld.Decompress(bytes.NewBufferString(text))
+		if err != nil {
+			t.Fatalf("Decompress(%q) error: %v", text, err)
+		}
+		content, _ := io.ReadAll(r)
+		if string(content) != text {
+			t.Errorf("Decompress(%q) = %q, want the text unchanged", text, content)
+		}
+	}
+}
+
+// compressExternal compresses data with a local command like xz or zstd,
+// the test is skipped, if the command is not installed
+func compressExternal(t *testing.T, command string, data []byte) []byte {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		t.Skipf("no local '%s' command", command)
+	}
+	cmd := exec.Command(path, "-c")
+	cmd.Stdin = bytes.NewReader(data)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%s -c error: %v", command, err)
+	}
+	return out
+}
+
+func TestDecompressExternal(t *testing.T) {
+	for _, command := range []string{"xz", "zstd"} {
+		t.Run(command, func(t *testing.T) {
+			compressed := compressExternal(t, command, []byte("a b\nc d\n"))
+			r, err := ld.Decompress(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatalf("Decompress() error: %v", err)
+			}
+			content, _ := io.ReadAll(r)
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+			if string(content) != "a b\nc d\n" {
+				t.Fatalf("Decompress() = %q, want %q", content, "a b\nc d\n")
+			}
+		})
+	}
+}
+
+func TestDecompressExternalCloseEarly(t *testing.T) {
+	// more output than the pipe buffer, the decoder blocks, if it is not stopped
+	compressed := compressExternal(t, "xz", []byte(strings.Repeat("a b c d e f g h\n", 100000)))
+	r, err := ld.Decompress(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Decompress() error: %v", err)
+	}
+	r.Read(make([]byte, 10))
+	done := make(chan error)
+	go func() { done <- r.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Close() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Close() blocks on the unread output of the decoder")
+	}
+}
+
+func TestGetDataCompressedFiles(t *testing.T) {
+	defer func() { ap.CmdParams.Files = nil }()
+
+	want, err := readTestDataFile("data.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+	for _, name := range []string{"data.txt", "data.txt.gz", "data.txt.bz2"} {
+		t.Run(name, func(t *testing.T) {
+			ap.CmdParams.Files = ap.T_filenames{getTestDataPath(name)}
+			erg := ld.GetData("")
+			if !reflect.DeepEqual(erg, want) {
+				t.Errorf(`GetData() for %s = %q, want %q`, name, erg, want)
+			}
+		})
+	}
+}
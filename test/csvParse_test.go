@@ -0,0 +1,70 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCsvParseQuotedFields(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Icsv = true
+
+	data, err := readTestDataFile("export.csv")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	want := df.T_parsedData{
+		df.T_dataline{`Name`, `Comment`, `Amount`},
+		df.T_dataline{`Alice`, `Hello, World`, `100`},
+		df.T_dataline{`Bob`, `He said "hi"`, `200`},
+		df.T_dataline{`Carol`, "two\nlines", `300`},
+	}
+	erg := df.DataParse(data, ',')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`DataParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestCsvParseTsv(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Itsv = true
+
+	data := df.T_rawdata{"a\tb c\t\"d\te\"", "1\t\t3"}
+	want := df.T_parsedData{
+		df.T_dataline{`a`, `b c`, "d\te"},
+		df.T_dataline{`1`, ``, `3`},
+	}
+	erg := df.DataParse(data, '\t')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`DataParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestCsvRoundTrip(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Icsv = true
+	ap.CmdParams.Csv = true
+	ap.CmdParams.Sep = ","
+
+	data := df.T_rawdata{
+		`Name,Comment,Amount`,
+		`Alice,"Hello, World",100`,
+		`Bob,"He said ""hi""",200`,
+		`Carol,"two`,
+		`lines",300`,
+	}
+	output := captureOutput(func() {
+		df.Format(df.DataParse(data, ','))
+	})
+	want := strings.Join(data, "\n") + "\n"
+	if output != want {
+		t.Fatalf("Format() = %q, want %q", output, want)
+	}
+}
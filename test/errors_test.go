@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	ap "pc/argparse"
+	"pc/table"
+	"strings"
+	"testing"
+)
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		err  *ap.T_error
+		want string
+	}{
+		{&ap.T_error{Err: errors.New("bad")}, "bad"},
+		{&ap.T_error{File: "a.csv", Err: errors.New("bad")}, "a.csv: bad"},
+		{&ap.T_error{File: "a.csv", Line: 3, Err: errors.New("bad")}, "a.csv:3: bad"},
+		{&ap.T_error{File: "a.csv", Line: 3, Column: 7, Err: errors.New("bad")}, "a.csv:3:7: bad"},
+		{&ap.T_error{Line: 3, Column: 7, Err: errors.New("bad")}, "3:7: bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestErrorAtKeepsClassAndPosition(t *testing.T) {
+	inner := ap.ErrorAt(ap.ErrData, errors.New("bad"), "", 2, 5)
+	err := ap.ErrorAt(ap.ErrInput, fmt.Errorf("wrapped: %w", inner), "in.csv", 9, 9)
+	if err.Class != ap.ErrData || err.File != "in.csv" || err.Line != 2 || err.Column != 5 {
+		t.Fatalf("ErrorAt() = %+v, want class ErrData at in.csv:2:5", err)
+	}
+	param := ap.NewError(ap.ErrParam, "bad parameter")
+	if got := ap.ErrorAt(ap.ErrData, param, "in.csv", 1, 1).Error(); got != "bad parameter" {
+		t.Fatalf("ErrorAt() of a parameter error = %q, want no position", got)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("other"), 1},
+		{ap.NewError(ap.ErrParam, "p"), 2},
+		{ap.NewError(ap.ErrInput, "i"), 3},
+		{fmt.Errorf("wrapped: %w", ap.NewError(ap.ErrData, "d")), 4},
+		{ap.NewError(ap.ErrOutput, "o"), 5},
+	}
+	for _, tt := range tests {
+		if got := ap.ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCheckValidatesRegexParams(t *testing.T) {
+	for _, p := range []ap.T_flags{
+		{Filter: ap.T_filters{"["}},
+		{Filter: ap.T_filters{"2=("}},
+		{Mark: "("},
+		{Pattern: "no groups"},
+	} {
+		p.Sep, p.Jarr, p.NoMatch, p.Sample, p.SortMem, p.Jobs = " ", "join", "drop", 1, 1, 1
+		if got := ap.ExitCode(ap.Check(&p)); got != int(ap.ErrParam) {
+			t.Errorf("Check(%+v) exit code = %d, want %d", p, got, ap.ErrParam)
+		}
+	}
+}
+
+func TestMalformedInputErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		opts   table.Options
+		line   int
+		column int
+	}{
+		{"csv quote", "a,b\n1,\"x\n", table.Options{Input: table.InputCSV}, 2, 5},
+		{"json syntax", "[{\"a\":1},\n{\"a\": }]", table.Options{Input: table.InputJSON}, 2, 8},
+		{"strict fields", "a b\n1 2\n1 2 3\n", table.Options{Strict: true}, 3, 3},
+		{"strict missing field", "a b\n1\n", table.Options{Strict: true}, 2, 2},
+	}
+	for _, tt := range tests {
+		_, err := table.Parse(strings.NewReader(tt.input), tt.opts)
+		var te *table.Error
+		if !errors.As(err, &te) {
+			t.Errorf("%s: Parse() error = %v, want table.Error", tt.name, err)
+			continue
+		}
+		if te.Class != table.ErrData || te.Line != tt.line || te.Column != tt.column {
+			t.Errorf("%s: Parse() error = %q class %d, want class %d at %d:%d",
+				tt.name, te, te.Class, ap.ErrData, tt.line, tt.column)
+		}
+	}
+}
+
+func TestLenientRows(t *testing.T) {
+	rows := [][]string{{"a", "b"}, {"2", "y"}, {"1"}}
+
+	var buf bytes.Buffer
+	if err := table.New(rows).Render(&buf, table.Options{SortCol: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a b\n1  \n2 y\n"; buf.String() != want {
+		t.Errorf("Render() sorted = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	opts := table.Options{Output: table.OutputJSON, Header: "x"}
+	if err := table.New(rows[1:2]).Render(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"2": "y"`) {
+		t.Errorf("Render() JSON without title = %q, want the column number as key", buf.String())
+	}
+
+	opts.Strict = true
+	if err := table.New(rows[1:2]).Render(&buf, opts); ap.ExitCode(err) != int(ap.ErrData) {
+		t.Errorf("Render() strict JSON without title error = %v, want a data error", err)
+	}
+	opts = table.Options{SortCol: 2, Strict: true}
+	if err := table.New(rows).Render(&buf, opts); ap.ExitCode(err) != int(ap.ErrData) {
+		t.Errorf("Render() strict sort of a short row error = %v, want a data error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"testing"
+)
+
+// resetFixedParams resets the parameters for fixed-width parsing
+func resetFixedParams() {
+	resetCmdParams()
+	ap.CmdParams.Fixed = true
+}
+
+func TestFixedWidthBoundsFromHeader(t *testing.T) {
+	resetFixedParams()
+	defer resetCmdParams()
+
+	data, err := readTestDataFile("dockerps.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	want := df.T_parsedData{
+		df.T_dataline{`CONTAINER ID`, `IMAGE`, `COMMAND`, `CREATED`, `STATUS`, `NAMES`},
+		df.T_dataline{`4c01db0b339c`, `nginx:latest`, `"/docker-entrypoint.…"`, `2 hours ago`, `Up 2 hours`, `web`},
+		df.T_dataline{`d7886598dbe2`, `redis`, `"docker-entrypoint.s…"`, `3 days ago`, `Up 3 days`, `cache 1`},
+	}
+	erg := df.DataParse(data, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`DataParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestFixedWidthRightAdjustedColumns(t *testing.T) {
+	resetFixedParams()
+	defer resetCmdParams()
+
+	data, err := readTestDataFile("df.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	// 'Used' and 'Available' are right adjusted, 'Mounted on' must stay one column
+	want := df.T_dataline{`tmpfs`, `8147532`, `0`, `8147532`, `0%`, `/dev/shm`}
+	erg := df.DataParse(data, ' ')
+	if len(erg) != 4 || !reflect.DeepEqual(erg[2], want) {
+		t.Fatalf(`DataParse() = %q, want line 3 %q`, erg, want)
+	}
+	if erg[0][5] != "Mounted on" {
+		t.Fatalf(`DataParse() header = %q, want last column "Mounted on"`, erg[0])
+	}
+}
+
+func TestFixedWidthExplicitWidths(t *testing.T) {
+	resetFixedParams()
+	defer resetCmdParams()
+	ap.CmdParams.Widths = []int{5, 3}
+
+	line := "abc  de fgh ij"
+	want := df.T_dataline{`abc`, `de`, `fgh ij`}
+	erg := df.FixedLineParse(line, df.BoundsFromWidths(ap.CmdParams.Widths))
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`FixedLineParse("%s") = %q, want %q`, line, erg, want)
+	}
+}
+
+func TestFixedWidthWideRunes(t *testing.T) {
+	resetFixedParams()
+	defer resetCmdParams()
+
+	// the wide runes occupy two display positions each
+	data := df.T_rawdata{
+		"NAME    CITY",
+		"東京駅  Tokyo",
+		"Bern    Bern",
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`NAME`, `CITY`},
+		df.T_dataline{`東京駅`, `Tokyo`},
+		df.T_dataline{`Bern`, `Bern`},
+	}
+	erg := df.DataParse(data, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`DataParse() = %q, want %q`, erg, want)
+	}
+}
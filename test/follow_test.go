@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// feedLines returns a closed channel holding the lines
+func feedLines(lines ...string) <-chan string {
+	ch := make(chan string, len(lines))
+	for _, l := range lines {
+		ch <- l
+	}
+	close(ch)
+	return ch
+}
+
+func TestFollowFormatGrowsWidths(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+
+	out := captureOutput(func() {
+		df.FollowFormat(feedLines("NAME AGE", "Bob 3", "Alexander 42"), ' ')
+	})
+	want := "NAME AGE\n" +
+		"Bob    3\n" +
+		"Alexander  42\n"
+	if out != want {
+		t.Fatalf("FollowFormat() =\n%q\nwant\n%q", out, want)
+	}
+}
+
+func TestFollowFormatHeaderOnWidthAndFilter(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.HeadWidth = true
+	ap.CmdParams.Filter = ap.T_filters{"a"}
+
+	out := captureOutput(func() {
+		df.FollowFormat(feedLines("NAME AGE", "Bob 3", "Carl 7", "Alexander 42"), ' ')
+	})
+	want := "NAME AGE\n" +
+		"Carl   7\n" +
+		"NAME      AGE\n" +
+		"Alexander  42\n"
+	if out != want {
+		t.Fatalf("FollowFormat() =\n%q\nwant\n%q", out, want)
+	}
+}
+
+func TestFollowFormatHeaderEvery(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.HeadEvery = 2
+
+	out := captureOutput(func() {
+		df.FollowFormat(feedLines("A B", "1 2", "3 4", "5 6"), ' ')
+	})
+	if n := strings.Count(out, "A B"); n != 2 {
+		t.Fatalf("FollowFormat() printed the header %d times, want 2:\n%s", n, out)
+	}
+}
+
+func TestFollowReadsAppendedLines(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(fname, []byte("first\nsecond\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines := make(chan string)
+	done := make(chan struct{})
+	go ld.Follow(fname, lines, done)
+
+	got := []string{<-lines, <-lines}
+	file, err := os.OpenFile(fname, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("thi")
+	file.Sync()
+	time.Sleep(300 * time.Millisecond)
+	file.WriteString("rd\n")
+	file.Close()
+
+	select {
+	case l := <-lines:
+		got = append(got, l)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Follow() did not send the appended line")
+	}
+	close(done)
+	for range lines {
+	}
+	want := []string{"first", "second", "third"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Follow() = %q, want %q", got, want)
+	}
+}
+
+func TestFollowBlockers(t *testing.T) {
+	for _, p := range []ap.T_flags{
+		{SortCol: 1}, {Gcol: 1}, {Csv: true}, {Json: true}, {Icsv: true}, {Itsv: true}, {Ijson: true},
+		{Ilogfmt: true}, {Istanza: true}, {Pattern: "(a)"}, {Union: true}, {Num: true}, {FileCol: true},
+	} {
+		p.Follow = true
+		p.Sep, p.Jarr, p.NoMatch, p.Sample, p.SortMem, p.Jobs = " ", "join", "drop", 1, 1, 1
+		if got := ap.ExitCode(ap.Check(&p)); got != int(ap.ErrParam) {
+			t.Errorf("Check(%+v) exit code = %d, want %d", p, got, ap.ErrParam)
+		}
+	}
+	p := ap.T_flags{Follow: true, Ts: true, Mark: "a", Where: "A > 1"}
+	p.Sep, p.Jarr, p.NoMatch, p.Sample, p.SortMem, p.Jobs = " ", "join", "drop", 1, 1, 1
+	if err := ap.Check(&p); err != nil {
+		t.Errorf("Check() with -follow, -ts, -mark and -where error = %v, want none", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	df "pc/dataformat"
+	"testing"
+)
+
+// benchmarkRows returns n parsed rows with a headline
+func benchmarkRows(n int) df.T_parsedData {
+	return df.DataParse(generateLines(n), ' ')
+}
+
+func BenchmarkGetMaxLength(b *testing.B) {
+	resetCmdParams()
+	data := benchmarkRows(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		df.GetMaxLength(data)
+	}
+}
+
+func BenchmarkGenerateLine(b *testing.B) {
+	resetCmdParams()
+	data := benchmarkRows(1000000)
+	maxlen := df.GetMaxLength(data)
+	cols := df.NewColumns(data, maxlen)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		row := append(df.T_dataline{}, data[i%len(data)]...)
+		row.GenerateLine(cols)
+	}
+}
+
+func BenchmarkPrintAsciiTab(b *testing.B) {
+	resetCmdParams()
+	data := benchmarkRows(1000000)
+	maxlen := df.GetMaxLength(data)
+	for i := range data {
+		data[i].GenerateLine(df.NewColumns(nil, maxlen))
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data.PrintAsciiTab(maxlen)
+	}
+}
+
+func TestGenerateLineAlignsNumbers(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	data := df.T_parsedData{
+		{"NAME", "SIZE", "AGE"},
+		{"a", "12Mi", "3d"},
+		{"bbbbbb", "1,5", "x"},
+	}
+	cols := df.NewColumns(data, df.GetMaxLength(data))
+	row := append(df.T_dataline{}, data[1]...)
+	row.GenerateLine(cols)
+	want := df.T_dataline{"a     ", "12Mi", " 3d"}
+	if len(row) != len(want) || row[0] != want[0] || row[1] != want[1] || row[2] != want[2] {
+		t.Fatalf("GenerateLine() = %q, want %q", row, want)
+	}
+}
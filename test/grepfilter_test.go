@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var grepInput = df.T_rawdata{
+	"TIME LEVEL MSG",
+	"10:00 info start",
+	"10:01 info healthcheck",
+	"10:02 ERROR disk",
+	"10:03 info after1",
+	"10:04 info after2",
+	"10:05 error healthcheck",
+	"10:06 info done",
+}
+
+func TestGrepFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func()
+		want []string // TIME of the selected rows
+	}{
+		{"case", func() { ap.CmdParams.Filter = ap.T_filters{"error"} }, []string{"10:05"}},
+		{"ignore case", func() { ap.CmdParams.Filter = ap.T_filters{"error"}; ap.CmdParams.FilterCase = true }, []string{"10:02", "10:05"}},
+		{"stacked", func() {
+			ap.CmdParams.Filter = ap.T_filters{"(?i)error", "3=^health"}
+		}, []string{"10:05"}},
+		{"invert", func() {
+			ap.CmdParams.Filter = ap.T_filters{"info", "3=^health"}
+			ap.CmdParams.FilterInv = true
+		}, []string{"10:00", "10:02", "10:03", "10:04", "10:05", "10:06"}},
+		{"after", func() { ap.CmdParams.Filter = ap.T_filters{"ERROR"}; ap.CmdParams.After = 2 }, []string{"10:02", "10:03", "10:04"}},
+		{"before", func() { ap.CmdParams.Filter = ap.T_filters{"ERROR"}; ap.CmdParams.Before = 5 }, []string{"10:00", "10:01", "10:02"}},
+		{"context", func() {
+			ap.CmdParams.Filter = ap.T_filters{"(?i)error"}
+			ap.CmdParams.After, ap.CmdParams.Before = 1, 1
+		}, []string{"10:01", "10:02", "10:03", "10:04", "10:05", "10:06"}},
+	}
+	for _, tt := range tests {
+		resetCmdParams()
+		tt.opts()
+		erg := df.DataParse(grepInput, ' ')
+		times := []string{}
+		for _, row := range erg[1:] {
+			times = append(times, row[0])
+		}
+		if erg[0][0] != "TIME" || !reflect.DeepEqual(times, tt.want) {
+			t.Errorf("%s: DataParse() = %q, want the headline and %q", tt.name, erg, tt.want)
+		}
+	}
+	resetCmdParams()
+}
+
+func TestGrepContextStreamMatchesFormat(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(fname, []byte(strings.Join(grepInput, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []func(){
+		func() { ap.CmdParams.After = 1 },
+		func() { ap.CmdParams.Before = 1 },
+		func() { ap.CmdParams.After, ap.CmdParams.Before = 2, 2 },
+		func() { ap.CmdParams.FilterInv = true; ap.CmdParams.Columns = ap.T_ColNumbers{3} },
+	} {
+		resetCmdParams()
+		ap.CmdParams.Filter = ap.T_filters{"(?i)error"}
+		opts()
+		want := captureOutput(func() { df.Format(df.DataParse(grepInput, ' ')) })
+		got := captureOutput(func() { df.Stream(fname, ' ') })
+		if got != want {
+			t.Errorf("Stream() =\n%s\nwant\n%s", got, want)
+		}
+	}
+	resetCmdParams()
+}
+
+func TestGrepContextCount(t *testing.T) {
+	in := strings.Join(grepInput, "\n")
+	opts := table.Options{Filter: []string{"error"}, FilterCase: true, After: 1}
+	tab, err := table.Parse(strings.NewReader(in), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tab.Rows) != 5 || tab.Matched != 2 || tab.Total != 7 {
+		t.Fatalf("Parse() = %q, %d of %d rows matched, want the headline, 4 rows and 2 of 7 matched", tab.Rows, tab.Matched, tab.Total)
+	}
+	if _, err := table.Parse(strings.NewReader(in), table.Options{Before: -1}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Fatalf("Parse() with negative Before error = %v, want a parameter error", err)
+	}
+}
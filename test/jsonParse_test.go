@@ -0,0 +1,91 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"testing"
+)
+
+// resetJsonParams resets the parameters for JSON input
+func resetJsonParams() {
+	resetCmdParams()
+	ap.CmdParams.Jarr = df.JsonArrayJoin
+}
+
+func TestJsonParseKubernetesList(t *testing.T) {
+	resetJsonParams()
+	defer resetJsonParams()
+	ap.CmdParams.Ijson = true
+
+	data, err := readTestDataFile("pods.json")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	want := df.T_parsedData{
+		df.T_dataline{`metadata.name`, `metadata.namespace`, `metadata.labels.app`, `status.phase`, `status.restarts`, `spec.containers`, `status.reason`},
+		df.T_dataline{`api-7d4b9`, `shop`, `api`, `Running`, `0`, `api,sidecar`, ``},
+		df.T_dataline{`db-0`, `shop`, ``, `Pending`, `4`, `postgres`, `Unschedulable`},
+	}
+	erg := df.DataParse(data, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`DataParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestJsonParseNDJSONArrays(t *testing.T) {
+	resetJsonParams()
+	defer resetJsonParams()
+
+	data := df.T_rawdata{
+		`{"name": "a", "tags": ["x", "y"], "ok": true}`,
+		`{"name": "b", "size": 1.5, "tags": [], "ok": null}`,
+	}
+	tests := []struct {
+		jarr string
+		want df.T_parsedData
+	}{
+		{df.JsonArrayJoin, df.T_parsedData{
+			df.T_dataline{`name`, `tags`, `ok`, `size`},
+			df.T_dataline{`a`, `x,y`, `true`, ``},
+			df.T_dataline{`b`, ``, ``, `1.5`},
+		}},
+		{df.JsonArrayIndex, df.T_parsedData{
+			df.T_dataline{`name`, `tags.0`, `tags.1`, `ok`, `size`},
+			df.T_dataline{`a`, `x`, `y`, `true`, ``},
+			df.T_dataline{`b`, ``, ``, ``, `1.5`},
+		}},
+		{df.JsonArrayJSON, df.T_parsedData{
+			df.T_dataline{`name`, `tags`, `ok`, `size`},
+			df.T_dataline{`a`, `["x","y"]`, `true`, ``},
+			df.T_dataline{`b`, `[]`, ``, `1.5`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jarr, func(t *testing.T) {
+			ap.CmdParams.Jarr = tt.jarr
+			erg := df.JsonParse(data)
+			if !reflect.DeepEqual(erg, tt.want) {
+				t.Errorf(`JsonParse() = %q, want %q`, erg, tt.want)
+			}
+		})
+	}
+}
+
+func TestJsonParseWithFilterAndSort(t *testing.T) {
+	resetJsonParams()
+	defer resetJsonParams()
+	ap.CmdParams.Ijson = true
+	ap.CmdParams.Filter = ap.T_filters{"b|c|name"}
+	ap.CmdParams.SortCol = 1
+
+	data := df.T_rawdata{`[{"id": 3, "name": "c"}, {"id": 1, "name": "a"}, {"id": 2, "name": "b"}]`}
+	output := captureOutput(func() {
+		df.Format(df.DataParse(data, ' '))
+	})
+	want := "id name\n 2 b   \n 3 c   \n"
+	if output != want {
+		t.Fatalf("Format() = %q, want %q", output, want)
+	}
+}
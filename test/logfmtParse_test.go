@@ -0,0 +1,26 @@
+package main
+
+import (
+	df "pc/dataformat"
+	"reflect"
+	"testing"
+)
+
+func TestLogfmtParse(t *testing.T) {
+	data := df.T_rawdata{
+		`level=info msg="started server" dur=12ms`,
+		``,
+		`level=error msg="bind failed: \"addr in use\"" port=8080 fatal`,
+		`msg='single quoted' level=debug`,
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`level`, `msg`, `dur`, `port`, `fatal`},
+		df.T_dataline{`info`, `started server`, `12ms`, ``, ``},
+		df.T_dataline{`error`, `bind failed: "addr in use"`, ``, `8080`, `true`},
+		df.T_dataline{`debug`, `single quoted`, ``, ``, ``},
+	}
+	erg := df.LogfmtParse(data)
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`LogfmtParse() = %q, want %q`, erg, want)
+	}
+}
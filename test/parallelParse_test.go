@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// generateLines returns a headline and n data lines with blank separated columns
+func generateLines(n int) df.T_rawdata {
+	data := make(df.T_rawdata, 0, n+1)
+	data = append(data, "ID NAME STATUS VALUE   COMMENT")
+	for i := 0; i < n; i++ {
+		data = append(data, fmt.Sprintf("%d pod-%d %s %d.%02d  \"text %d\"", i, i%977, []string{"Running", "Pending", "Failed"}[i%3], i%1000, i%100, i))
+	}
+	return data
+}
+
+func TestParallelParseKeepsOrder(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	data := generateLines(50000)
+
+	for _, setup := range []func(){
+		func() {},
+		func() { ap.CmdParams.Filter = ap.T_filters{"Failed"} },
+		func() { ap.CmdParams.MoreBlanks = true },
+	} {
+		resetCmdParams()
+		setup()
+		ap.CmdParams.Jobs = 1
+		want := df.DataParse(data, ' ')
+		ap.CmdParams.Jobs = 8
+		got := df.DataParse(data, ' ')
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("parallel DataParse() differs from sequential parsing")
+		}
+	}
+}
+
+// benchmarkDataParse parses two million lines with the given number of workers
+func benchmarkDataParse(b *testing.B, jobs int, setup func()) {
+	resetCmdParams()
+	defer resetCmdParams()
+	setup()
+	ap.CmdParams.Jobs = jobs
+	data := generateLines(2000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		df.DataParse(data, ' ')
+	}
+}
+
+func BenchmarkDataParseSequential(b *testing.B) {
+	benchmarkDataParse(b, 1, func() {})
+}
+
+func BenchmarkDataParseParallel(b *testing.B) {
+	benchmarkDataParse(b, runtime.NumCPU(), func() {})
+}
+
+func BenchmarkDataParseMoreBlanksFilterSequential(b *testing.B) {
+	benchmarkDataParse(b, 1, func() { ap.CmdParams.MoreBlanks = true; ap.CmdParams.Filter = ap.T_filters{"Fail"} })
+}
+
+func BenchmarkDataParseMoreBlanksFilterParallel(b *testing.B) {
+	benchmarkDataParse(b, runtime.NumCPU(), func() { ap.CmdParams.MoreBlanks = true; ap.CmdParams.Filter = ap.T_filters{"Fail"} })
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestPatternParseNoMatch(t *testing.T) {
+	defer func() { ap.CmdParams.NoMatch = "" }()
+
+	data, err := readTestDataFile("app.log")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+	re := regexp.MustCompile(`(?P<ts>\S+ \S+) (?P<level>\w+) (?P<msg>.*)`)
+
+	tests := []struct {
+		nomatch string
+		want    df.T_parsedData
+	}{
+		{df.NoMatchDrop, df.T_parsedData{
+			df.T_dataline{`ts`, `level`, `msg`},
+			df.T_dataline{`2024-01-01 10:00:00`, `INFO`, `started`},
+			df.T_dataline{`2024-01-01 10:00:01`, `ERROR`, `boom`},
+			df.T_dataline{`2024-01-01 10:00:02`, `INFO`, `ok`},
+		}},
+		{df.NoMatchKeep, df.T_parsedData{
+			df.T_dataline{`ts`, `level`, `msg`, `UNMATCHED`},
+			df.T_dataline{`2024-01-01 10:00:00`, `INFO`, `started`, ``},
+			df.T_dataline{`2024-01-01 10:00:01`, `ERROR`, `boom`, ``},
+			df.T_dataline{``, ``, ``, `  at foo()`},
+			df.T_dataline{``, ``, ``, `  at bar()`},
+			df.T_dataline{`2024-01-01 10:00:02`, `INFO`, `ok`, ``},
+		}},
+		{df.NoMatchAppend, df.T_parsedData{
+			df.T_dataline{`ts`, `level`, `msg`},
+			df.T_dataline{`2024-01-01 10:00:00`, `INFO`, `started`},
+			df.T_dataline{`2024-01-01 10:00:01`, `ERROR`, "boom\n  at foo()\n  at bar()"},
+			df.T_dataline{`2024-01-01 10:00:02`, `INFO`, `ok`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nomatch, func(t *testing.T) {
+			ap.CmdParams.NoMatch = tt.nomatch
+			erg := df.PatternParse(data, re)
+			if !reflect.DeepEqual(erg, tt.want) {
+				t.Errorf(`PatternParse() = %q, want %q`, erg, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternParseUnnamedGroups(t *testing.T) {
+	data := df.T_rawdata{"a=1", "b=2"}
+	want := df.T_parsedData{
+		df.T_dataline{`1`, `value`},
+		df.T_dataline{`a`, `1`},
+		df.T_dataline{`b`, `2`},
+	}
+	erg := df.PatternParse(data, regexp.MustCompile(`(\w+)=(?P<value>\d+)`))
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`PatternParse() = %q, want %q`, erg, want)
+	}
+}
@@ -39,6 +39,7 @@ func resetCmdParams() {
 	ap.CmdParams.Sep = " "
 	ap.CmdParams.Header = ""
 	ap.CmdParams.Columns = nil
+	ap.CmdParams.ColSpecs = nil
 	ap.CmdParams.Rh = false
 	ap.CmdParams.SortCol = 0
 	ap.CmdParams.Num = false
@@ -48,6 +49,39 @@ func resetCmdParams() {
 	ap.CmdParams.Fs = false
 	ap.CmdParams.Gcol = 0
 	ap.CmdParams.Nhl = false
+	ap.CmdParams.Filter = nil
+	ap.CmdParams.FilterInv = false
+	ap.CmdParams.FilterCase = false
+	ap.CmdParams.After = 0
+	ap.CmdParams.Before = 0
+	ap.CmdParams.Fixed = false
+	ap.CmdParams.Widths = nil
+	ap.CmdParams.Icsv = false
+	ap.CmdParams.Itsv = false
+	ap.CmdParams.Ijson = false
+	ap.CmdParams.Ilogfmt = false
+	ap.CmdParams.Istanza = false
+	ap.CmdParams.Pattern = ""
+	ap.CmdParams.FileCol = false
+	ap.CmdParams.LineCol = false
+	ap.CmdParams.Union = false
+	ap.CmdParams.Null = ""
+	ap.CmdParams.Follow = false
+	ap.CmdParams.HeadEvery = 0
+	ap.CmdParams.HeadWidth = false
+	ap.CmdParams.Strict = false
+	ap.CmdParams.Ragged = ""
+	ap.CmdParams.Where = ""
+	ap.CmdParams.Lead = 0
+	ap.CmdParams.Rename = ""
+	ap.CmdParams.Count = false
+	ap.CmdParams.Watch = 0
+	ap.CmdParams.Key = 1
+	ap.CmdParams.Command = nil
+	ap.CmdParams.Stream = false
+	ap.CmdParams.Sample = 1000
+	ap.CmdParams.Jobs = 4
+	ap.CmdParams.SortMem = 256
 }
 
 func TestPrintAsciiTabMultilineCell(t *testing.T) {
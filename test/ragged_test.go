@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var raggedInput = df.T_rawdata{
+	"TIME LEVEL MSG",
+	"10:00 info started",
+	"10:01 warn disk is almost full",
+	"10:02 error",
+}
+
+func TestRaggedPolicies(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   df.T_parsedData
+	}{
+		{df.RaggedKeep, df.T_parsedData{
+			{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started"},
+			{"10:01", "warn", "disk", "is", "almost", "full"}, {"10:02", "error"},
+		}},
+		{df.RaggedPad, df.T_parsedData{
+			{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started"},
+			{"10:01", "warn", "disk", "is", "almost", "full"}, {"10:02", "error", ""},
+		}},
+		{df.RaggedTruncate, df.T_parsedData{
+			{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started"},
+			{"10:01", "warn", "disk"}, {"10:02", "error", ""},
+		}},
+		{df.RaggedMergeLast, df.T_parsedData{
+			{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started"},
+			{"10:01", "warn", "disk is almost full"}, {"10:02", "error", ""},
+		}},
+		{df.RaggedReport, df.T_parsedData{
+			{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started"},
+			{"10:01", "warn", "disk", "is", "almost", "full"}, {"10:02", "error"},
+		}},
+	}
+	for _, tt := range tests {
+		resetCmdParams()
+		ap.CmdParams.Ragged = tt.policy
+		var erg df.T_parsedData
+		report := captureStderr(func() { erg = df.DataParse(raggedInput, ' ') })
+		if !reflect.DeepEqual(erg, tt.want) {
+			t.Errorf("DataParse() with -ragged=%s = %q, want %q", tt.policy, erg, tt.want)
+		}
+		wantReport := ""
+		if tt.policy == df.RaggedReport {
+			wantReport = "3: row has 6 fields, the headline has 3\n4: row has 2 fields, the headline has 3\n"
+		}
+		if report != wantReport {
+			t.Errorf("report of -ragged=%s = %q, want %q", tt.policy, report, wantReport)
+		}
+	}
+	resetCmdParams()
+}
+
+// captureStderr returns, what f prints to STDERR
+func captureStderr(f func()) string {
+	old := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	f()
+	w.Close()
+	os.Stderr = old
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestRaggedMergeLastWithSeparatorAndHeader(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Ragged = df.RaggedMergeLast
+	ap.CmdParams.Header = "ID;NOTE"
+	ap.CmdParams.Nhl = true
+
+	erg := df.DataParse(df.T_rawdata{"1;a;b;c", "2;d"}, ';')
+	want := df.T_parsedData{{"1", "a;b;c"}, {"2", "d"}}
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf("DataParse() = %q, want %q", erg, want)
+	}
+}
+
+func TestRaggedMergeLastKeepsSpacing(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Ragged = df.RaggedMergeLast
+	ap.CmdParams.MoreBlanks = true
+
+	erg := df.DataParse(df.T_rawdata{"TIME   LEVEL  MSG", "10:00  info   started   app  now  ", "10:01  warn   disk  is full"}, ' ')
+	want := df.T_parsedData{{"TIME", "LEVEL", "MSG"}, {"10:00", "info", "started   app  now"}, {"10:01", "warn", "disk  is full"}}
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf("DataParse() with -mb = %q, want %q", erg, want)
+	}
+
+	ap.CmdParams.MoreBlanks = false
+	erg = df.DataParse(df.T_rawdata{"A B", "x y   z\tw"}, ' ')
+	want = df.T_parsedData{{"A", "B"}, {"x", "y   z\tw"}}
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf("DataParse() = %q, want %q", erg, want)
+	}
+}
+
+func TestRaggedError(t *testing.T) {
+	in := strings.Join(raggedInput, "\n")
+	_, err := table.Parse(strings.NewReader(in), table.Options{Ragged: df.RaggedError})
+	var te *table.Error
+	if !errors.As(err, &te) || te.Class != table.ErrData || te.Line != 3 || te.Column != 4 {
+		t.Fatalf("Parse() error = %v, want a data error at line 3, column 4", err)
+	}
+	if _, err := table.Parse(strings.NewReader(in), table.Options{Ragged: "fill"}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Fatalf("Parse() with unknown policy error = %v, want a parameter error", err)
+	}
+}
+
+func TestRaggedReportStreamAndFollow(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Ragged = df.RaggedReport
+	fname := filepath.Join(t.TempDir(), "r.txt")
+	if err := os.WriteFile(fname, []byte(strings.Join(raggedInput, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first pass over the file for the column widths reports nothing
+	ap.CmdParams.Files = ap.T_filenames{fname}
+	report := captureStderr(func() { captureOutput(func() { df.Stream(fname, ' ') }) })
+	want := fname + ":3: row has 6 fields, the headline has 3\n" + fname + ":4: row has 2 fields, the headline has 3\n"
+	if report != want {
+		t.Errorf("report of Stream() = %q, want %q", report, want)
+	}
+
+	ap.CmdParams.Files = nil
+	report = captureStderr(func() { captureOutput(func() { df.FollowFormat(feedLines(raggedInput...), ' ') }) })
+	want = "STDIN:3: row has 6 fields, the headline has 3\nSTDIN:4: row has 2 fields, the headline has 3\n"
+	if report != want {
+		t.Errorf("report of FollowFormat() = %q, want %q", report, want)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameColumns(t *testing.T) {
+	tests := []struct {
+		rename string
+		want   []string
+	}{
+		{"2=Pod,STATUS=State", []string{"NAME", "Pod", "State", "NAME"}},
+		{"name=Pod", []string{"Pod", "READY", "STATUS", "Pod"}},
+		{"4=Copy,NAME=Pod", []string{"Pod", "READY", "STATUS", "Pod"}},
+		{"STATUS=READY,READY=x", []string{"NAME", "x", "READY", "NAME"}},
+	}
+	for _, tt := range tests {
+		header := []string{"NAME", "READY", "STATUS", "NAME"}
+		if err := ap.RenameColumns(header, tt.rename); err != nil || !reflect.DeepEqual(header, tt.want) {
+			t.Errorf("RenameColumns(%s) = %q, %v, want %q", tt.rename, header, err, tt.want)
+		}
+	}
+	for _, rename := range []string{"FOO=x", "5=x", "0=x", "x", "=x"} {
+		err := ap.RenameColumns([]string{"NAME", "READY"}, rename)
+		if ap.ExitCode(err) != int(ap.ErrParam) {
+			t.Errorf("RenameColumns(%s) error = %v, want a parameter error", rename, err)
+		}
+	}
+}
+
+func TestRenameOutput(t *testing.T) {
+	rows := [][]string{{"NAME", "STATUS"}, {"api", "Running"}}
+	tests := []struct {
+		opts table.Options
+		want string
+	}{
+		{table.Options{Rename: "2=State", Columns: []int{2, 1}}, "STATUS  State\n"},
+		{table.Options{Rename: "NAME=Pod", Select: []string{"NAME", "STATUS", "NAME"}, Output: table.OutputCSV}, "Pod,STATUS,Pod\napi,Running,api\n"},
+		{table.Options{Rename: "1=Pod,2=State", Output: table.OutputJSON}, `"Pod",`},
+		{table.Options{Rename: "1=Pod", Output: table.OutputJSON, TitleSep: true}, `"Pods": [`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := table.New(rows).Render(&buf, tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("Render(%+v) = %q, want %q", tt.opts, buf.String(), tt.want)
+		}
+	}
+	var buf bytes.Buffer
+	if err := table.New(rows).Render(&buf, table.Options{Rename: "AGE=x"}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Errorf("Render() with an unknown title error = %v, want a parameter error", err)
+	}
+}
+
+func TestRenameHeaderJSON(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Header = "N S X"
+	ap.CmdParams.Json = true
+	ap.CmdParams.Columns = ap.T_ColNumbers{2, 1}
+	ap.CmdParams.Rename = "N=Name"
+	out := captureOutput(func() { df.Format(df.DataParse(df.T_rawdata{"api Running 1"}, ' ')) })
+	if want := "[\n  {\n    \"S\": \"Running\",\n    \"Name\": \"api\"\n  }\n]\n"; out != want {
+		t.Fatalf("Format() = %q, want %q", out, want)
+	}
+}
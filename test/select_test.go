@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var selectInput = df.T_rawdata{
+	"PID USER CMD",
+	"1 root init",
+	"22 dirk java",
+	"33 root sshd",
+	"44 dirk bash",
+}
+
+func TestFilterKeepsHeadline(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func()
+		data df.T_rawdata
+		want df.T_parsedData
+	}{
+		{"headline", func() {}, selectInput,
+			df.T_parsedData{{"PID", "USER", "CMD"}, {"22", "dirk", "java"}, {"44", "dirk", "bash"}}},
+		{"nhl", func() { ap.CmdParams.Nhl = true }, selectInput,
+			df.T_parsedData{{"22", "dirk", "java"}, {"44", "dirk", "bash"}}},
+		{"header", func() { ap.CmdParams.Header = "P U C" }, selectInput[1:],
+			df.T_parsedData{{"22", "dirk", "java"}, {"44", "dirk", "bash"}}},
+		{"lead", func() { ap.CmdParams.Lead = 1 }, selectInput,
+			df.T_parsedData{{"PID", "USER", "CMD"}, {"1", "root", "init"}, {"22", "dirk", "java"}, {"44", "dirk", "bash"}}},
+		{"lead nhl", func() { ap.CmdParams.Lead = 1; ap.CmdParams.Nhl = true }, selectInput,
+			df.T_parsedData{{"PID", "USER", "CMD"}, {"22", "dirk", "java"}, {"44", "dirk", "bash"}}},
+	}
+	for _, tt := range tests {
+		resetCmdParams()
+		ap.CmdParams.Filter = ap.T_filters{"dirk"}
+		tt.opts()
+		if erg := df.DataParse(tt.data, ' '); !reflect.DeepEqual(erg, tt.want) {
+			t.Errorf("%s: DataParse() = %q, want %q", tt.name, erg, tt.want)
+		}
+	}
+	resetCmdParams()
+}
+
+func TestFilterTitleSeparator(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Filter = ap.T_filters{"java"}
+	ap.CmdParams.Ts = true
+	out := captureOutput(func() { df.Format(df.DataParse(selectInput, ' ')) })
+	if want := "PID USER CMD \n=== ==== ====\n 22 dirk java\n"; out != want {
+		t.Fatalf("Format() = %q, want %q", out, want)
+	}
+}
+
+func TestCountFooter(t *testing.T) {
+	in := strings.Join(selectInput, "\n")
+	tab, err := table.Parse(strings.NewReader(in), table.Options{Where: `USER == "root"`, Filter: []string{"sshd"}, Lead: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab.Matched != 1 || tab.Total != 3 {
+		t.Fatalf("Parse() matched %d of %d rows, want 1 of 3", tab.Matched, tab.Total)
+	}
+	var buf bytes.Buffer
+	if err := tab.Render(&buf, table.Options{Count: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "PID USER CMD \n  1 root init\n 33 root sshd\n1 of 3 rows matched\n"; buf.String() != want {
+		t.Fatalf("Render() = %q, want %q", buf.String(), want)
+	}
+	buf.Reset()
+	footer := captureStderr(func() { err = tab.Render(&buf, table.Options{Count: true, Output: table.OutputCSV}) })
+	if err != nil || strings.Contains(buf.String(), "matched") || footer != "1 of 3 rows matched\n" {
+		t.Fatalf("Render() CSV = %q, footer %q, want the footer on STDERR only", buf.String(), footer)
+	}
+	if _, err := table.Parse(strings.NewReader(in), table.Options{Lead: -1}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Fatalf("Parse() with negative Lead error = %v, want a parameter error", err)
+	}
+}
+
+func TestStreamCountFooter(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	fname := filepath.Join(t.TempDir(), "ps.txt")
+	if err := os.WriteFile(fname, []byte(strings.Join(selectInput, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ap.CmdParams.Filter = ap.T_filters{"dirk"}
+	ap.CmdParams.Lead = 1
+	ap.CmdParams.Count = true
+	out := captureOutput(func() { df.Stream(fname, ' ') })
+	want := "PID USER CMD \n  1 root init\n 22 dirk java\n 44 dirk bash\n2 of 3 rows matched\n"
+	if out != want {
+		t.Fatalf("Stream() = %q, want %q", out, want)
+	}
+}
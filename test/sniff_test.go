@@ -0,0 +1,71 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    df.T_rawdata
+		layout  df.T_layout
+		sep     rune
+		columns int
+	}{
+		{name: "csv export", file: "export.csv", layout: df.LayoutDelimited, sep: ',', columns: 3},
+		{name: "docker ps", file: "dockerps.txt", layout: df.LayoutMoreBlanks, sep: ' ', columns: 6},
+		{name: "df", file: "df.txt", layout: df.LayoutFixed, sep: ' ', columns: 6},
+		{name: "quoted blanks", file: "alerts.txt", layout: df.LayoutBlanks, sep: ' ', columns: 6},
+		{name: "semicolon", data: df.T_rawdata{"a;b c;d", "1;2;3", "4;5 6;7"}, layout: df.LayoutDelimited, sep: ';', columns: 3},
+		{name: "pipe", data: df.T_rawdata{"a|b|c", "1|2|3"}, layout: df.LayoutDelimited, sep: '|', columns: 3},
+		{name: "ndjson", data: df.T_rawdata{`{"a":1}`, `{"a":2,"b":"x y"}`}, layout: df.LayoutNDJSON},
+		{name: "json", data: df.T_rawdata{`[`, `  {"a": 1},`, `  {"a": 2}`, `]`}, layout: df.LayoutJSON},
+		{name: "bracket log", data: df.T_rawdata{`[INFO] started app`, `[WARN] stopped db`, `[INFO] started web`}, layout: df.LayoutBlanks, sep: ' ', columns: 3},
+		{name: "brace log", data: df.T_rawdata{`{main} a=1 b=2`, `{worker} a=3 b=4`}, layout: df.LayoutBlanks, sep: ' ', columns: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if tt.file != "" {
+				var err error
+				if data, err = readTestDataFile(tt.file); err != nil {
+					t.Fatalf("Failed to read test data: %v", err)
+				}
+			}
+			f := df.DetectFormat(data, df.SniffLines)
+			if f.Layout != tt.layout || f.Sep != tt.sep || (tt.columns > 0 && f.Columns != tt.columns) {
+				t.Errorf("DetectFormat() = %v (sep %q), want %s (sep %q) with %d columns", f, f.Sep, tt.layout, tt.sep, tt.columns)
+			}
+			if f.Confidence <= 0 || f.Confidence > 1 {
+				t.Errorf("DetectFormat() confidence = %v, want 0 < confidence <= 1", f.Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectFormatNoJSON(t *testing.T) {
+	for _, data := range []df.T_rawdata{
+		{`[`, `  {"a": 1},`, `  {"a": 2},`},
+		{`[2024-05-01 10:00] job started`, `[2024-05-01 10:05] job done, 3 files`},
+		{`{"a":1}`, `a=2 b=3`, `a=4 b=5`},
+	} {
+		f := df.DetectFormat(data, df.SniffLines)
+		if f.Layout == df.LayoutJSON || f.Layout == df.LayoutNDJSON {
+			t.Errorf("DetectFormat(%q) = %v, want no JSON", data, f)
+		}
+	}
+}
+
+func TestApplyFormatDelimited(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+
+	df.ApplyFormat(df.T_format{Layout: df.LayoutDelimited, Sep: ';', Columns: 3, Confidence: 1})
+	if !ap.CmdParams.Icsv || ap.CmdParams.Sep != ";" {
+		t.Fatalf("ApplyFormat() set icsv=%v sep=%q, want icsv=true sep=';'", ap.CmdParams.Icsv, ap.CmdParams.Sep)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSourcesParseSingleHeadline(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.FileCol = true
+	ap.CmdParams.LineCol = true
+
+	sources := []ld.T_source{
+		{Name: "day1.txt", Lines: []string{"NAME AGE", "api 1d", "db 2d"}},
+		{Name: "day2.txt", Lines: []string{"NAME AGE", "web 3d"}},
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`FILE`, `LINE`, `NAME`, `AGE`},
+		df.T_dataline{`day1.txt`, `2`, `api`, `1d`},
+		df.T_dataline{`day1.txt`, `3`, `db`, `2d`},
+		df.T_dataline{`day2.txt`, `2`, `web`, `3d`},
+	}
+	erg := df.SourcesParse(sources, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`SourcesParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestSourcesParseLineNumbersWithFilter(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.LineCol = true
+	ap.CmdParams.Nhl = true
+	ap.CmdParams.Filter = ap.T_filters{"b"}
+
+	// the quoted value spans two input lines
+	sources := []ld.T_source{
+		{Name: ld.StdinName, Lines: []string{"a \"x\ny\"", "b 1", "c 2", "b 3"}},
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`3`, `b`, `1`},
+		df.T_dataline{`5`, `b`, `3`},
+	}
+	erg := df.SourcesParse(sources, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`SourcesParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestFilenamesGlob(t *testing.T) {
+	var files ap.T_filenames
+	if err := files.Set(getTestDataPath("data.txt*")); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Set() = %v, want data.txt, data.txt.bz2 and data.txt.gz", files)
+	}
+	if err := files.Set(getTestDataPath("nothing*.txt")); err == nil {
+		t.Fatalf("Set() for a glob without match should return an error")
+	}
+}
+
+func TestSourcesParseHeaderKeepsFirstLines(t *testing.T) {
+	sources := []ld.T_source{
+		{Name: "h1.txt", Lines: []string{"a 1", "b 2"}},
+		{Name: "h2.txt", Lines: []string{"c 3", "d 4"}},
+	}
+	tests := []struct {
+		fcol, lcol bool
+		want       df.T_parsedData
+		headline   string
+		json       string
+	}{
+		{false, false, df.T_parsedData{{`a`, `1`}, {`b`, `2`}, {`c`, `3`}, {`d`, `4`}}, "A B", `"A": "a"`},
+		{true, false, df.T_parsedData{{`h1.txt`, `a`, `1`}, {`h1.txt`, `b`, `2`}, {`h2.txt`, `c`, `3`}, {`h2.txt`, `d`, `4`}}, "FILE   A B", `"FILE": "h1.txt"`},
+		{true, true, df.T_parsedData{{`h1.txt`, `1`, `a`, `1`}, {`h1.txt`, `2`, `b`, `2`}, {`h2.txt`, `1`, `c`, `3`}, {`h2.txt`, `2`, `d`, `4`}}, "FILE   LINE A B", `"LINE": "1"`},
+	}
+	for _, tt := range tests {
+		resetCmdParams()
+		ap.CmdParams.Header = "A B"
+		ap.CmdParams.FileCol = tt.fcol
+		ap.CmdParams.LineCol = tt.lcol
+		erg := df.SourcesParse(sources, ' ')
+		if !reflect.DeepEqual(erg, tt.want) {
+			t.Errorf(`SourcesParse() with -header, -fcol=%v and -lcol=%v = %q, want %q`, tt.fcol, tt.lcol, erg, tt.want)
+		}
+		out := captureOutput(func() { df.Format(df.SourcesParse(sources, ' ')) })
+		if headline, _, _ := strings.Cut(out, "\n"); strings.TrimRight(headline, " ") != tt.headline {
+			t.Errorf(`Format() with -header, -fcol=%v and -lcol=%v headline = %q, want %q`, tt.fcol, tt.lcol, headline, tt.headline)
+		}
+		ap.CmdParams.Json = true
+		if out := captureOutput(func() { df.Format(df.SourcesParse(sources, ' ')) }); !strings.Contains(out, tt.json) {
+			t.Errorf(`Format() with -json, -header, -fcol=%v and -lcol=%v = %s, want %s`, tt.fcol, tt.lcol, out, tt.json)
+		}
+	}
+	resetCmdParams()
+}
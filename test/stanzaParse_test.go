@@ -0,0 +1,47 @@
+package main
+
+import (
+	df "pc/dataformat"
+	"reflect"
+	"testing"
+)
+
+func TestStanzaParseCpuinfo(t *testing.T) {
+	data, err := readTestDataFile("cpuinfo.txt")
+	if err != nil {
+		t.Fatalf("Failed to read test data: %v", err)
+	}
+
+	want := df.T_parsedData{
+		df.T_dataline{`processor`, `vendor_id`, `model name`, `flags`, `cache size`},
+		df.T_dataline{`0`, `GenuineIntel`, `Intel(R) Xeon(R) CPU @ 2.20GHz`, `fpu vme de`, ``},
+		df.T_dataline{`1`, `GenuineIntel`, `Intel(R) Xeon(R) CPU @ 2.20GHz`, `fpu vme`, `56320 KB`},
+	}
+	erg := df.StanzaParse(data)
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`StanzaParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestStanzaParseContinuationAndPairs(t *testing.T) {
+	data := df.T_rawdata{
+		`Name:    web`,
+		`Labels:  app=web`,
+		`         tier=front`,
+		`Environment=A=1 B=2`,
+		``,
+		``,
+		`NAME="sda" SIZE="10G"`,
+		`NAME="sda1" SIZE="1G"`,
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`Name`, `Labels`, `Environment`, `NAME`, `SIZE`},
+		df.T_dataline{`web`, "app=web\ntier=front", `A=1 B=2`, ``, ``},
+		df.T_dataline{``, ``, ``, `sda`, `10G`},
+		df.T_dataline{``, ``, ``, `sda1`, `1G`},
+	}
+	erg := df.StanzaParse(data)
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`StanzaParse() = %q, want %q`, erg, want)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamMatchesFormat(t *testing.T) {
+	for _, opts := range []func(){
+		func() {},
+		func() { ap.CmdParams.Pp = true },
+		func() { ap.CmdParams.Ts = true; ap.CmdParams.Columns = ap.T_ColNumbers{1, 5} },
+		func() { ap.CmdParams.Filter = ap.T_filters{"tmpfs|Filesystem"}; ap.CmdParams.Fixed = true },
+	} {
+		resetCmdParams()
+		opts()
+		lines, err := readTestDataFile("df.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := captureOutput(func() {
+			df.Format(df.DataParse(lines, ' '))
+		})
+		got := captureOutput(func() {
+			df.Stream(getTestDataPath("df.txt"), ' ')
+		})
+		if got != want {
+			t.Errorf("Stream() =\n%s\nwant\n%s", got, want)
+		}
+	}
+	resetCmdParams()
+}
+
+func TestCanStreamFallsBack(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Stream = true
+	if !df.CanStream() {
+		t.Fatal("CanStream() = false, want true for plain line input")
+	}
+	ap.CmdParams.Gcol = 2
+	if df.CanStream() {
+		t.Fatal("CanStream() = true, want false with -gcol")
+	}
+}
+
+func TestStreamFileMergesQuotedLines(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "quoted.txt")
+	content := "NAME NOTE\nweb \"first line\nsecond line\"\ndb ok\n"
+	if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines := make(chan string)
+	go ld.StreamFile(fname, lines)
+	got := []string{}
+	for l := range lines {
+		got = append(got, l)
+	}
+	want := []string{"NAME NOTE", "web \"first line\nsecond line\"", "db ok"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("StreamFile() = %q, want %q", got, want)
+	}
+}
+
+// captureToFile returns the output of f written to STDOUT, for outputs larger than a pipe buffer
+func captureToFile(t *testing.T, f func()) string {
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = out
+	f()
+	os.Stdout = old
+	out.Close()
+	content, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestStreamSortSpillsRuns(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.SortCol = 2
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	content := sortInput()
+	fname := filepath.Join(t.TempDir(), "big.txt")
+	if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	want := captureToFile(t, func() {
+		df.Format(df.DataParse(lines, ' '))
+	})
+	ap.CmdParams.SortMem = 1
+	got := captureToFile(t, func() {
+		df.Stream(fname, ' ')
+	})
+	if got != want {
+		t.Fatalf("Stream() with spilled runs differs from Format()")
+	}
+	if files, _ := os.ReadDir(tmp); len(files) != 0 {
+		t.Fatalf("temporary files are not removed: %v", files)
+	}
+}
+
+// sortInput returns about 2 MB of rows, so -sortmem=1 spills at least one run before the merge
+func sortInput() string {
+	var content strings.Builder
+	content.WriteString("ID KEY VALUE\n")
+	for i := 0; i < 12000; i++ {
+		fmt.Fprintf(&content, "%d k%03d %s\n", i, (i*7919)%997, strings.Repeat("x", i%50))
+	}
+	return content.String()
+}
+
+func TestCanStreamLargeSort(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.SortCol = 2
+	ap.CmdParams.SortMem = 1
+
+	// a file of more than 1 MB is sorted in stream mode also without -stream
+	fname := filepath.Join(t.TempDir(), "big.txt")
+	content := strings.Repeat(sortInput(), 3)
+	if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ap.CmdParams.Files = ap.T_filenames{fname}
+	if !df.CanStream() {
+		t.Errorf("CanStream() for -sortcol of a file larger than -sortmem = false, want true")
+	}
+	ap.CmdParams.Strict = true
+	if df.CanStream() {
+		t.Errorf("CanStream() with -strict = true, want false")
+	}
+	ap.CmdParams.Strict = false
+	ap.CmdParams.Csv = true
+	if df.CanStream() {
+		t.Errorf("CanStream() with -csv = true, want false")
+	}
+	ap.CmdParams.Csv = false
+	ap.CmdParams.SortMem = 256
+	if df.CanStream() {
+		t.Errorf("CanStream() for a file smaller than -sortmem = true, want false")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	ap "pc/argparse"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableParseAndRender(t *testing.T) {
+	in := "NAME AGE\nbob 42\nalice 7\n"
+	tab, err := table.Parse(strings.NewReader(in), table.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"NAME", "AGE"}, {"bob", "42"}, {"alice", "7"}}
+	if !reflect.DeepEqual(tab.Rows, want) {
+		t.Fatalf("Parse() = %q, want %q", tab.Rows, want)
+	}
+
+	var buf bytes.Buffer
+	if err := tab.Render(&buf, table.Options{PrettyPrint: true, SortCol: 1}); err != nil {
+		t.Fatal(err)
+	}
+	wantOut := "" +
+		"| ----- | --- |\n" +
+		"| NAME  | AGE |\n" +
+		"| ----- | --- |\n" +
+		"| alice |   7 |\n" +
+		"| bob   |  42 |\n" +
+		"| ----- | --- |\n"
+	if buf.String() != wantOut {
+		t.Fatalf("Render() =\n%s\nwant\n%s", buf.String(), wantOut)
+	}
+	// rendering does not change the rows of the table
+	if !reflect.DeepEqual(tab.Rows, want) {
+		t.Fatalf("Rows after Render() = %q, want %q", tab.Rows, want)
+	}
+}
+
+func TestTableParseOptions(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("name,note\nbob,\"a, b\"\nalice,c\n"))
+	zw.Close()
+
+	tab, err := table.Parse(&gz, table.Options{Input: table.InputCSV, Filter: []string{"1=^al"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"name", "note"}, {"alice", "c"}}
+	if !reflect.DeepEqual(tab.Rows, want) {
+		t.Fatalf("Parse() = %q, want %q", tab.Rows, want)
+	}
+}
+
+func TestTableRenderFormats(t *testing.T) {
+	tab := table.New([][]string{{"NAME", "AGE"}, {"bob", "42"}})
+	tests := []struct {
+		opts table.Options
+		want string
+	}{
+		{table.Options{Output: table.OutputCSV, Columns: []int{2, 1}}, "AGE,NAME\n42,bob\n"},
+		{table.Options{Output: table.OutputJSON}, "[\n  [\n    \"NAME\",\n    \"AGE\"\n  ],\n  [\n    \"bob\",\n    \"42\"\n  ]\n]\n"},
+		{table.Options{RemoveHeader: true, NoNumbers: true}, "bob 42\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tab.Render(&buf, tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Render(%+v) = %q, want %q", tt.opts, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestTableReturnsErrors(t *testing.T) {
+	tab := table.New([][]string{{"a"}})
+	for _, opts := range []table.Options{
+		{Mark: "("},
+		{Output: "xml"},
+		{Input: "yaml"},
+		{JsonArrays: "flat"},
+	} {
+		if err := tab.Render(&bytes.Buffer{}, opts); err == nil {
+			t.Errorf("Render(%+v) returned no error", opts)
+		}
+	}
+	if _, err := table.Parse(strings.NewReader("a b\n"), table.Options{Filter: []string{"["}}); err == nil {
+		t.Error("Parse() with invalid filter returned no error")
+	}
+	if _, err := table.Parse(strings.NewReader("{\"a\":"), table.Options{Input: table.InputJSON}); err == nil {
+		t.Error("Parse() with invalid JSON returned no error")
+	}
+}
+
+func TestTableIgnoresCmdParams(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Pp = true
+	ap.CmdParams.Csv = true
+	ap.CmdParams.Columns = ap.T_ColNumbers{2}
+	before := ap.CmdParams
+
+	var buf bytes.Buffer
+	if err := table.New([][]string{{"a", "b"}}).Render(&buf, table.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a b\n" {
+		t.Fatalf("Render() = %q, want %q", buf.String(), "a b\n")
+	}
+	if !reflect.DeepEqual(ap.CmdParams, before) {
+		t.Fatal("Render() changed the command line parameters")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+	"reflect"
+	"testing"
+)
+
+func TestUnionAlignsByHeader(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Union = true
+	ap.CmdParams.Null = "-"
+	ap.CmdParams.Icsv = true
+
+	sources := []ld.T_source{
+		{Name: "day1.csv", Lines: []string{"Name,Amount,Date", "Alice,100,d1"}},
+		{Name: "day2.csv", Lines: []string{"Date,Name,Region", "d2,Bob,EU", "d2,Carl,US,extra"}},
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`Name`, `Amount`, `Date`, `Region`, `4`},
+		df.T_dataline{`Alice`, `100`, `d1`, `-`, `-`},
+		df.T_dataline{`Bob`, `-`, `d2`, `EU`, `-`},
+		df.T_dataline{`Carl`, `-`, `d2`, `US`, `extra`},
+	}
+	erg := df.SourcesParse(sources, ',')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`SourcesParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestUnionKeepsHeadlineWithFilterAndFileColumn(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Union = true
+	ap.CmdParams.FileCol = true
+	ap.CmdParams.Filter = ap.T_filters{"Bob"}
+
+	sources := []ld.T_source{
+		{Name: "a.txt", Lines: []string{"NAME AGE", "Alice 30"}},
+		{Name: "b.txt", Lines: []string{"AGE NAME", "40 Bob"}},
+	}
+	want := df.T_parsedData{
+		df.T_dataline{`FILE`, `NAME`, `AGE`},
+		df.T_dataline{`b.txt`, `Bob`, `40`},
+	}
+	erg := df.SourcesParse(sources, ' ')
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf(`SourcesParse() = %q, want %q`, erg, want)
+	}
+}
+
+func TestUnionWithoutHeadlines(t *testing.T) {
+	for _, p := range []ap.T_flags{
+		{Union: true, Nhl: true},
+		{Union: true, Header: "N V"},
+	} {
+		p.Sep, p.Jarr, p.NoMatch, p.Sample, p.SortMem, p.Jobs = " ", "join", "drop", 1, 1, 1
+		if got := ap.ExitCode(ap.Check(&p)); got != int(ap.ErrParam) {
+			t.Errorf("Check() with -union, -nhl=%v and -header=%q exit code = %d, want %d", p.Nhl, p.Header, got, ap.ErrParam)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	ap "pc/argparse"
+	df "pc/dataformat"
+	ld "pc/loaddata"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchFormatMarksChangedCells(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+
+	first := df.T_parsedData{
+		{"NAME", "STATUS"},
+		{"web", "Running"},
+		{"old", "Running"},
+	}
+	second := df.T_parsedData{
+		{"NAME", "STATUS"},
+		{"web", "Failed"},
+		{"new", "Pending"},
+	}
+	var prev df.T_watchRows
+	out := captureOutput(func() {
+		prev, _ = df.WatchFormat(first, nil)
+	})
+	if strings.Contains(out, "\033[") {
+		t.Fatalf("first refresh must not be colored:\n%q", out)
+	}
+
+	var stats df.T_watchStats
+	out = captureOutput(func() {
+		_, stats = df.WatchFormat(second, prev)
+	})
+	want := "NAME STATUS \n" +
+		"web  \033[7mFailed \033[0m\n" +
+		"\033[32mnew \033[0m \033[32mPending\033[0m\n"
+	if out != want {
+		t.Fatalf("WatchFormat() =\n%q\nwant\n%q", out, want)
+	}
+	wantStats := df.T_watchStats{Rows: 2, Added: 1, Removed: 1, Changed: 1}
+	if stats != wantStats {
+		t.Fatalf("WatchFormat() stats = %+v, want %+v", stats, wantStats)
+	}
+}
+
+func TestWatchFormatKeyColumn(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Key = 2
+
+	prev := df.T_watchRows{"a1": {"web", "a1", "1"}}
+	var stats df.T_watchStats
+	captureOutput(func() {
+		_, stats = df.WatchFormat(df.T_parsedData{{"NAME", "ID", "N"}, {"api", "a1", "1"}}, prev)
+	})
+	wantStats := df.T_watchStats{Rows: 1, Changed: 1}
+	if stats != wantStats {
+		t.Fatalf("WatchFormat() stats = %+v, want %+v", stats, wantStats)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	src, err := ld.RunCommand([]string{"sh", "-c", "printf 'A B\\n1 2\\n'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ld.T_source{Name: `sh -c printf 'A B\n1 2\n'`, Lines: []string{"A B", "1 2"}}
+	if !reflect.DeepEqual(src, want) {
+		t.Fatalf("RunCommand() = %q, want %q", src, want)
+	}
+
+	src, err = ld.RunCommand([]string{"sh", "-c", "echo x; echo broken >&2; exit 3"})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("RunCommand() error = %v, want the STDERR message", err)
+	}
+	if !reflect.DeepEqual(src.Lines, []string{"x"}) {
+		t.Fatalf("RunCommand() lines = %q, want the output of the failed command", src.Lines)
+	}
+}
+
+func TestWatchBlockers(t *testing.T) {
+	for _, p := range []ap.T_flags{{Gcol: 1}, {Num: true}, {Count: true}, {Csv: true}, {Json: true}} {
+		p.Watch, p.Command = time.Second, []string{"date"}
+		p.Sep, p.Jarr, p.NoMatch, p.Sample, p.SortMem, p.Jobs = " ", "join", "drop", 1, 1, 1
+		if got := ap.ExitCode(ap.Check(&p)); got != int(ap.ErrParam) {
+			t.Errorf("Check(%+v) exit code = %d, want %d", p, got, ap.ErrParam)
+		}
+	}
+}
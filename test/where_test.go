@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	ap "pc/argparse"
+	df "pc/dataformat"
+	"pc/table"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var whereInput = df.T_rawdata{
+	"NAME STATUS RESTARTS",
+	"api-7f9 Running 0",
+	"api-8c1 CrashLoopBackOff 12",
+	"web-1a2 Running 5",
+	"db-0 Pending 0",
+	"web-x9 Error 4",
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string // NAME of the selected rows
+	}{
+		{`status != "Running" && restarts > 3 || name ~ /^api-/`, []string{"api-7f9", "api-8c1", "web-x9"}},
+		{`STATUS != "Running" and not (RESTARTS > 3)`, []string{"db-0"}},
+		{`$3 >= 5`, []string{"api-8c1", "web-1a2"}},
+		{`$3 < 10`, []string{"api-7f9", "web-1a2", "db-0", "web-x9"}},
+		{`$3 > "10"`, []string{"api-8c1"}},
+		{`$2 > "Pending"`, []string{"api-7f9", "web-1a2"}},
+		{`NAME !~ "^(api|web)" || STATUS == 'Error'`, []string{"db-0", "web-x9"}},
+		{`(NAME ~ /^web/ || NAME ~ /^db/) && !(RESTARTS == 0)`, []string{"web-1a2", "web-x9"}},
+		{`$4`, []string{}},
+	}
+	for _, tt := range tests {
+		resetCmdParams()
+		ap.CmdParams.Where = tt.expr
+		erg := df.DataParse(whereInput, ' ')
+		names := []string{}
+		for _, row := range erg[1:] {
+			names = append(names, row[0])
+		}
+		if !reflect.DeepEqual(erg[0], df.T_dataline{"NAME", "STATUS", "RESTARTS"}) || !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("DataParse() with -where=%s = %q, want the headline and %q", tt.expr, erg, tt.want)
+		}
+	}
+	resetCmdParams()
+}
+
+func TestWhereWithHeader(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Header = "N S R"
+	ap.CmdParams.Where = "R > 3"
+
+	erg := df.DataParse(whereInput[1:], ' ')
+	want := df.T_parsedData{{"api-8c1", "CrashLoopBackOff", "12"}, {"web-1a2", "Running", "5"}, {"web-x9", "Error", "4"}}
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf("DataParse() = %q, want %q", erg, want)
+	}
+}
+
+func TestWhereUnicodeTitles(t *testing.T) {
+	resetCmdParams()
+	defer resetCmdParams()
+	ap.CmdParams.Where = `Größe > 2 && Straße ~ /^Mühl/`
+
+	erg := df.DataParse(df.T_rawdata{"Name Größe Straße", "a 1 Mühlweg", "b 3 Mühlweg", "c 5 Hauptstraße"}, ' ')
+	want := df.T_parsedData{{"Name", "Größe", "Straße"}, {"b", "3", "Mühlweg"}}
+	if !reflect.DeepEqual(erg, want) {
+		t.Fatalf("DataParse() = %q, want %q", erg, want)
+	}
+	_, err := df.ParseWhere("Größe > 2 ¶", df.T_dataline{"Größe"})
+	if err == nil || !strings.Contains(err.Error(), "'¶' at position 11") {
+		t.Errorf("ParseWhere() error = %v, want the unexpected '¶' at position 11", err)
+	}
+}
+
+func TestWhereErrors(t *testing.T) {
+	for _, expr := range []string{
+		`RESTARTS > 3 &&`,
+		`(NAME == "x"`,
+		`NAME ~ /[/`,
+		`NAME ~`,
+		`NAME == "x`,
+		`unknown == 1`,
+		`$0 == 1`,
+		`NAME NAME`,
+	} {
+		_, err := df.ParseWhere(expr, df.T_dataline{"NAME", "STATUS", "RESTARTS"})
+		var pe *ap.T_error
+		if !errors.As(err, &pe) || pe.Class != ap.ErrParam {
+			t.Errorf("ParseWhere(%s) error = %v, want a parameter error", expr, err)
+		}
+	}
+	in := strings.Join(whereInput, "\n")
+	if _, err := table.Parse(strings.NewReader(in), table.Options{Where: "bad =="}); ap.ExitCode(err) != int(table.ErrParam) {
+		t.Errorf("Parse() with a broken -where error = %v, want a parameter error", err)
+	}
+	tab, err := table.Parse(strings.NewReader(in), table.Options{Where: `status == "Pending"`})
+	if err != nil || len(tab.Rows) != 2 || tab.Rows[1][0] != "db-0" {
+		t.Errorf("Parse() with -where = %q, %v, want the headline and db-0", tab, err)
+	}
+}